@@ -0,0 +1,102 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// An AlertRule contains a Grafana alert rule.
+// Legacy dashboard alerts (Grafana 4.0 to 10.x, with unified alerting disabled) set ID, State and URL,
+// unified alerting rules (Grafana 9.1 and later) set UID, FolderUID, RuleGroup, Labels and Annotations.
+type AlertRule struct {
+	ID           int               `json:"id,omitempty"`
+	UID          string            `json:"uid,omitempty"`
+	Title        string            `json:"title"`
+	State        string            `json:"state,omitempty"`
+	URL          string            `json:"url,omitempty"`
+	DashboardUID string            `json:"dashboardUid,omitempty"`
+	PanelID      int               `json:"panelId,omitempty"`
+	FolderUID    string            `json:"folderUID,omitempty"`
+	RuleGroup    string            `json:"ruleGroup,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// legacyAlert contains the json structure of a alert returned by /api/alerts.
+type legacyAlert struct {
+	ID           int    `json:"id"`
+	DashboardUID string `json:"dashboardUid"`
+	PanelID      int    `json:"panelId"`
+	Name         string `json:"name"`
+	State        string `json:"state"`
+	URL          string `json:"url"`
+}
+
+// GetAlertRules returns the alert rules of the session organization.
+// The legacy alerts API is tried first, it exists up to Grafana 10.x when unified alerting is disabled.
+// When it is not found, the alerting provisioning API of Grafana 9.1 and later is used.
+// Grafana 9.0 with unified alerting has neither and is not supported.
+// It returns a error matching ErrUnsupportedVersion if alerting is not available with the server version.
+func (s *Session) GetAlertRules() ([]AlertRule, error) {
+	return s.GetAlertRulesContext(context.Background())
+}
+
+// GetAlertRulesContext is like GetAlertRules but uses the provided context for the requests.
+func (s *Session) GetAlertRulesContext(ctx context.Context) (rules []AlertRule, err error) {
+	v, err := s.requireVersion(ctx, 4, 0, "GetAlertRules")
+	if err != nil {
+		return
+	}
+
+	rules, err = s.getLegacyAlertRules(ctx)
+	if err == nil || !v.AtLeast(9, 0) || !errors.Is(err, ErrNotFound) {
+		return
+	}
+
+	// unified alerting is enabled, the legacy API is not registered
+	if !v.AtLeast(9, 1) {
+		err = unsupportedVersion(v, "GetAlertRules")
+		return
+	}
+	reqURL := s.url + "/api/v1/provisioning/alert-rules"
+	err = s.doJSON(ctx, "GET", reqURL, nil, &rules)
+	for i := range rules {
+		rules[i].DashboardUID = rules[i].Annotations["__dashboardUid__"]
+		rules[i].PanelID, _ = strconv.Atoi(rules[i].Annotations["__panelId__"])
+	}
+	return
+}
+
+// getLegacyAlertRules returns the dashboard alerts of the legacy alerts API.
+func (s *Session) getLegacyAlertRules(ctx context.Context) (rules []AlertRule, err error) {
+	var alerts []legacyAlert
+	reqURL := s.url + "/api/alerts"
+	if err = s.doJSON(ctx, "GET", reqURL, nil, &alerts); err != nil {
+		return
+	}
+	for _, alert := range alerts {
+		rules = append(rules, AlertRule{
+			ID:           alert.ID,
+			Title:        alert.Name,
+			State:        alert.State,
+			URL:          alert.URL,
+			DashboardUID: alert.DashboardUID,
+			PanelID:      alert.PanelID,
+		})
+	}
+	return
+}
@@ -0,0 +1,61 @@
+package grafanaclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAlertServer returns a server with the legacy alerts API, or with unified alerting.
+func newAlertServer(legacy bool) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/alerts", func(w http.ResponseWriter, r *http.Request) {
+		if !legacy {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"id":1,"dashboardId":2,"dashboardUid":"nmon","panelId":3,"name":"CPU high","state":"alerting","url":"/d/nmon/nmon"}]`))
+	})
+	mux.HandleFunc("/api/v1/provisioning/alert-rules", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":5,"uid":"cpu-high","title":"CPU high","folderUID":"ops","ruleGroup":"nmon",
+			"labels":{"team":"unix"},"annotations":{"__dashboardUid__":"nmon","__panelId__":"3"}}]`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func Test_GetAlertRules(t *testing.T) {
+	legacySrv := newAlertServer(true)
+	defer legacySrv.Close()
+	unifiedSrv := newAlertServer(false)
+	defer unifiedSrv.Close()
+
+	legacyRules := []AlertRule{{ID: 1, Title: "CPU high", State: "alerting", URL: "/d/nmon/nmon", DashboardUID: "nmon", PanelID: 3}}
+	for _, version := range []string{"6.7.4", "9.0.9", "9.5.2"} {
+		session, _ := NewSessionWithOptions(legacySrv.URL, WithServerVersion(version))
+		rules, err := session.GetAlertRules()
+		assert.Nil(t, err, "We are expecting no error and got one listing legacy alerts with "+version)
+		assert.Equal(t, legacyRules, rules)
+	}
+
+	session, _ := NewSessionWithOptions(unifiedSrv.URL, WithServerVersion("10.2.3"))
+	rules, err := session.GetAlertRules()
+	assert.Nil(t, err, "We are expecting no error and got one listing alert rules")
+	assert.Len(t, rules, 1)
+	assert.Equal(t, "cpu-high", rules[0].UID)
+	assert.Equal(t, "ops", rules[0].FolderUID)
+	assert.Equal(t, "nmon", rules[0].DashboardUID)
+	assert.Equal(t, 3, rules[0].PanelID)
+
+	for _, version := range []string{"3.1.1", "9.0.9"} {
+		session, _ = NewSessionWithOptions(unifiedSrv.URL, WithServerVersion(version))
+		_, err = session.GetAlertRules()
+		assert.True(t, errors.Is(err, ErrUnsupportedVersion), "We are expecting a unsupported version error with "+version)
+	}
+
+	session, _ = NewSessionWithOptions(unifiedSrv.URL, WithServerVersion("8.3.0"))
+	_, err = session.GetAlertRules()
+	assert.True(t, errors.Is(err, ErrNotFound), "We are expecting the legacy API error before Grafana 9.0")
+}
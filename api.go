@@ -17,16 +17,15 @@ package grafanaclient
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
-	"net/http/cookiejar"
+	neturl "net/url"
 	"os"
-	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -34,36 +33,20 @@ import (
 	"github.com/naoina/toml"
 )
 
-const timeout = 5
-
-var protocolRegexp = regexp.MustCompile(`^https://`)
-
-// GrafanaError is a error structure to handle error messages in this library
-type GrafanaError struct {
-	Code        int
-	Description string
-}
-
-// A GrafanaMessage contains the json error message received when http request failed
-type GrafanaMessage struct {
-	Message string `json:"message"`
-}
-
-// Error generate a text error message.
-// If Code is zero, we know it's not a http error.
-func (h GrafanaError) Error() string {
-	if h.Code != 0 {
-		return fmt.Sprintf("HTTP %d: %s", h.Code, h.Description)
-	}
-	return fmt.Sprintf("ERROR: %s", h.Description)
-}
-
 // Session contains user credentials, url and a pointer to http client session.
+// When Token is set, every request is authenticated with a bearer token
+// and no DoLogon call is needed.
 type Session struct {
-	client   *http.Client
-	User     string
-	Password string
-	url      string
+	client    *http.Client
+	User      string
+	Password  string
+	Token     string
+	url       string
+	basicAuth bool
+	retry     *RetryPolicy
+	limiter   *rateLimiter
+	orgID     int
+	version   *versionCache
 }
 
 // A Login contains the json structure of Grafana authentication request
@@ -74,20 +57,28 @@ type Login struct {
 }
 
 // A DataSource contains the json structure of Grafana DataSource
+// Type specific settings are stored in JSONData and secrets in SecureJSONData.
+// Grafana never returns the secrets, SecureJSONFields lists the ones which are set.
 type DataSource struct {
-	ID                int    `json:"Id"`
-	OrgID             int    `json:"orgId"`
-	Name              string `json:"name"`
-	Type              string `json:"type"`
-	Access            string `json:"access"`
-	URL               string `json:"url"`
-	Password          string `json:"password"`
-	User              string `json:"user"`
-	Database          string `json:"database"`
-	BasicAuth         bool   `json:"basicAuth"`
-	BasicAuthUser     string `json:"basicAuthUser"`
-	BasicAuthPassword string `json:"basicAuthPassword"`
-	IsDefault         bool   `json:"isDefault"`
+	ID                int             `json:"Id"`
+	UID               string          `json:"uid,omitempty"`
+	OrgID             int             `json:"orgId"`
+	Name              string          `json:"name"`
+	Type              string          `json:"type"`
+	Access            string          `json:"access"`
+	URL               string          `json:"url"`
+	Password          string          `json:"password"`
+	User              string          `json:"user"`
+	Database          string          `json:"database"`
+	BasicAuth         bool            `json:"basicAuth"`
+	BasicAuthUser     string          `json:"basicAuthUser"`
+	BasicAuthPassword string          `json:"basicAuthPassword"`
+	WithCredentials   bool            `json:"withCredentials"`
+	IsDefault         bool            `json:"isDefault"`
+	JSONData          JSONData        `json:"jsonData,omitempty"`
+	SecureJSONData    SecureJSONData  `json:"secureJsonData,omitempty"`
+	SecureJSONFields  map[string]bool `json:"secureJsonFields,omitempty"`
+	ReadOnly          bool            `json:"readOnly"`
 }
 
 // A DataSourcePlugin contains the json structure of Grafana DataSource plugin
@@ -143,13 +134,19 @@ type PluginPartial struct {
 }
 
 // A DashboardUploader encapsulates a complete Dashboard
+// FolderUID, or FolderID with Grafana servers older than 8.0, sets the folder of the dashboard,
+// the General folder is used when both are empty. Message is recorded in the dashboard version history.
 type DashboardUploader struct {
 	Dashboard Dashboard `json:"dashboard"`
 	Overwrite bool      `json:"overwrite"`
+	FolderID  int       `json:"folderId,omitempty"`
+	FolderUID string    `json:"folderUid,omitempty"`
+	Message   string    `json:"message,omitempty"`
 }
 
 // A DashboardResult contains the response from Grafana when requesting a Dashboard.
 // It contains the Dashboard itself and the meta data.
+// Grafana 2.0 sends the Dashboard as model, newer versions as dashboard: both are decoded in Model.
 type DashboardResult struct {
 	Meta  Meta      `json:"meta"`
 	Model Dashboard `json:"model"`
@@ -157,38 +154,51 @@ type DashboardResult struct {
 
 // A Meta contains a Dashboard metadata.
 type Meta struct {
-	Created    string `json:"created"`
-	Expires    string `json:"expires"`
-	IsHome     bool   `json:"isHome"`
-	IsSnapshot bool   `json:"isSnapshot"`
-	IsStarred  bool   `json:"isStarred"`
-	Slug       string `json:"slug"`
+	Created     string `json:"created"`
+	Updated     string `json:"updated,omitempty"`
+	Expires     string `json:"expires"`
+	IsHome      bool   `json:"isHome"`
+	IsSnapshot  bool   `json:"isSnapshot"`
+	IsStarred   bool   `json:"isStarred"`
+	Slug        string `json:"slug"`
+	UID         string `json:"uid,omitempty"`
+	URL         string `json:"url,omitempty"`
+	FolderID    int    `json:"folderId,omitempty"`
+	FolderUID   string `json:"folderUid,omitempty"`
+	FolderTitle string `json:"folderTitle,omitempty"`
+	Version     int    `json:"version,omitempty"`
 }
 
 // A Dashboard contains the Dashboard structure.
+// The fields unknown to Dashboard, Row, Panel, Target and their components are kept
+// in their Extra map and sent back when uploading the dashboard.
 type Dashboard struct {
-	Editable        bool          `json:"editable"`
-	HideControls    bool          `json:"hideControls"`
-	ID              int           `json:"id"`
-	OriginalTitle   string        `json:"originalTitle"`
-	Refresh         string        `json:"refresh"`
-	Annotations     Annotation    `json:"annotations"`
-	SchemaVersion   int           `json:"schemaVersion"`
-	SharedCrosshair bool          `json:"sharedCrosshair"`
-	Style           string        `json:"style"`
-	Templating      Templating    `json:"templating,omitempty" toml:"templates"`
-	Tags            []interface{} `json:"tags"`
-	GTime           GTime         `json:"time" toml:"time"`
-	Rows            []Row         `json:"rows" toml:"row"`
-	Title           string        `json:"title"`
-	Version         int           `json:"version"`
-	Timezone        string        `json:"timezone"`
+	Editable        bool                       `json:"editable"`
+	HideControls    bool                       `json:"hideControls"`
+	ID              int                        `json:"id"`
+	UID             string                     `json:"uid,omitempty"`
+	OriginalTitle   string                     `json:"originalTitle"`
+	Refresh         interface{}                `json:"refresh"`
+	Annotations     Annotation                 `json:"annotations"`
+	SchemaVersion   int                        `json:"schemaVersion"`
+	SharedCrosshair bool                       `json:"sharedCrosshair"`
+	Style           string                     `json:"style"`
+	Templating      Templating                 `json:"templating,omitempty" toml:"templates"`
+	Tags            []interface{}              `json:"tags"`
+	GTime           GTime                      `json:"time" toml:"time"`
+	Rows            []Row                      `json:"rows,omitempty" toml:"row"`
+	Panels          []Panel                    `json:"panels,omitempty" toml:"panel"`
+	Title           string                     `json:"title"`
+	Version         int                        `json:"version"`
+	Timezone        string                     `json:"timezone"`
+	Extra           map[string]json.RawMessage `json:"-" toml:"-"`
+	absent          map[string]bool
 }
 
 // A GTime contains the Dadhboard informations on the time frame of the data.
 type GTime struct {
 	From string `json:"from"`
-	Now  bool   `json:"now"`
+	Now  bool   `json:"now,omitempty"`
 	To   string `json:"to"`
 }
 
@@ -198,28 +208,37 @@ type Templating struct {
 }
 
 //Template define a variable usable in Grafana
+// Query is a string, or an object like {"query":"label_values(job)","refId":"A"} with Grafana 9 and later.
 type Template struct {
-	AllFormat string `json:"allFormat"`
-	Current   struct {
-		Tags  []interface{} `json:"tags"`
-		Text  string        `json:"text"`
-		Value interface{}   `json:"value"`
-	} `json:"current,omitempty"`
-	Datasource  string `json:"datasource"`
-	IncludeAll  bool   `json:"includeAll"`
-	Multi       bool   `json:"multi"`
-	MultiFormat string `json:"multiFormat"`
-	Name        string `json:"name"`
+	AllFormat   string          `json:"allFormat"`
+	Current     TemplateCurrent `json:"current,omitempty"`
+	Datasource  string          `json:"datasource,omitempty"`
+	IncludeAll  bool            `json:"includeAll"`
+	Multi       bool            `json:"multi"`
+	MultiFormat string          `json:"multiFormat"`
+	Name        string          `json:"name"`
 	Options     []struct {
 		Selected bool   `json:"selected"`
 		Text     string `json:"text"`
 		Value    string `json:"value"`
 	} `json:"options,omitempty"`
-	Query         string `json:"query"`
-	Refresh       string `json:"refresh"`
-	RefreshOnLoad bool   `json:"refresh_on_load"`
-	Regex         string `json:"regex"`
-	Type          string `json:"type"`
+	Query         interface{}                `json:"query"`
+	Refresh       interface{}                `json:"refresh"`
+	RefreshOnLoad bool                       `json:"refresh_on_load"`
+	Regex         string                     `json:"regex"`
+	Type          string                     `json:"type"`
+	Extra         map[string]json.RawMessage `json:"-" toml:"-"`
+	absent        map[string]bool
+}
+
+// A TemplateCurrent contains the selected value of a Template.
+// Text and Value are a string, or an array of strings for a multi-value variable.
+type TemplateCurrent struct {
+	Tags   []interface{}              `json:"tags"`
+	Text   interface{}                `json:"text"`
+	Value  interface{}                `json:"value"`
+	Extra  map[string]json.RawMessage `json:"-" toml:"-"`
+	absent map[string]bool
 }
 
 //Templates is an Array of Template
@@ -227,64 +246,77 @@ type Templates []Template
 
 // A Annotation contains the current annotations of a dashboard
 type Annotation struct {
-	Enable bool          `json:"enable"`
+	Enable bool          `json:"enable,omitempty"`
 	List   []interface{} `json:"list"`
 }
 
 // A Row is a dashboard Row it can contains multiple panels
 type Row struct {
-	Collapse bool    `json:"collapse"`
-	Editable bool    `json:"editable"`
-	Height   string  `json:"height"`
-	Panels   []Panel `json:"panels" toml:"panel"`
-	Title    string  `json:"title"`
+	Collapse bool                       `json:"collapse"`
+	Editable bool                       `json:"editable"`
+	Height   string                     `json:"height"`
+	Panels   []Panel                    `json:"panels" toml:"panel"`
+	Title    string                     `json:"title"`
+	Extra    map[string]json.RawMessage `json:"-" toml:"-"`
+	absent   map[string]bool
 }
 
 // A Panel is a component of a Row. It can be a chart, a text or a single stat panel
+// With the grid layout of Grafana 5.0 and later, panels are placed by GridPos at the dashboard level,
+// and a row is a panel of type row which contains its panels when Collapsed.
 type Panel struct {
-	Content         string           `json:"content"`
-	Editable        bool             `json:"editable"`
-	Error           bool             `json:"error"`
-	ID              int              `json:"id"`
-	Mode            string           `json:"mode"`
-	Span            int              `json:"span"`
-	Style           struct{}         `json:"style"`
-	Title           string           `json:"title"`
-	Type            string           `json:"type"`
-	Fill            int              `json:"fill"`
-	Stack           bool             `json:"stack"`
-	Targets         []Target         `json:"targets" toml:"target"`
-	Metrics         []Metric         `json:"-" toml:"metric"`
-	SeriesOverrides []SeriesOverride `json:"seriesOverrides,omitempty" toml:"override"`
-	Tooltip         Tooltip          `json:"tooltip,omitempty"`
-	PageSize        int              `json:"pageSize,omitempty" toml:"pageSize,omitempty"`
-	Legend          Legend           `json:"legend,omitempty"`
-	LeftYAxisLabel  string           `json:"leftYAxisLabel,omitempty"`
-	RightYAxisLabel string           `json:"rightYAxisLabel,omitempty"`
-	DataSource      string           `json:"datasource,omitempty"`
-	NullPointMode   string           `json:"nullPointMode,omitempty"`
-	ValueName       string           `json:"valueName,omitempty"`
-	Lines           bool             `json:"lines,omitempty"`
-	Linewidth       int              `json:"linewidth,omitempty"`
-	Points          bool             `json:"points,omitempty"`
-	Pointradius     int              `json:"pointradius,omitempty"`
-	Bars            bool             `json:"bars,omitempty"`
-	Percentage      bool             `json:"percentage,omitempty"`
-	SteppedLine     bool             `json:"steppedLine,omitempty"`
-	TimeFrom        interface{}      `json:"timeFrom,omitempty"`
-	TimeShift       interface{}      `json:"timeShift,omitempty"`
+	Content         string                     `json:"content"`
+	Editable        bool                       `json:"editable"`
+	Error           bool                       `json:"error"`
+	ID              int                        `json:"id"`
+	Mode            string                     `json:"mode"`
+	Span            int                        `json:"span"`
+	Style           struct{}                   `json:"style"`
+	Title           string                     `json:"title"`
+	Type            string                     `json:"type"`
+	Fill            int                        `json:"fill"`
+	Stack           bool                       `json:"stack"`
+	Targets         []Target                   `json:"targets" toml:"target"`
+	Metrics         []Metric                   `json:"-" toml:"metric"`
+	SeriesOverrides []SeriesOverride           `json:"seriesOverrides,omitempty" toml:"override"`
+	Tooltip         Tooltip                    `json:"tooltip,omitempty"`
+	PageSize        int                        `json:"pageSize,omitempty" toml:"pageSize,omitempty"`
+	Legend          Legend                     `json:"legend,omitempty"`
+	LeftYAxisLabel  string                     `json:"leftYAxisLabel,omitempty"`
+	RightYAxisLabel string                     `json:"rightYAxisLabel,omitempty"`
+	DataSource      string                     `json:"datasource,omitempty"`
+	NullPointMode   string                     `json:"nullPointMode,omitempty"`
+	ValueName       string                     `json:"valueName,omitempty"`
+	Lines           bool                       `json:"lines,omitempty"`
+	Linewidth       int                        `json:"linewidth,omitempty"`
+	Points          bool                       `json:"points,omitempty"`
+	Pointradius     interface{}                `json:"pointradius,omitempty"`
+	Bars            bool                       `json:"bars,omitempty"`
+	Percentage      bool                       `json:"percentage,omitempty"`
+	SteppedLine     bool                       `json:"steppedLine,omitempty"`
+	TimeFrom        interface{}                `json:"timeFrom,omitempty"`
+	TimeShift       interface{}                `json:"timeShift,omitempty"`
+	GridPos         *GridPos                   `json:"gridPos,omitempty" toml:"gridPos,omitempty"`
+	Collapsed       bool                       `json:"collapsed,omitempty" toml:"collapsed,omitempty"`
+	Panels          []Panel                    `json:"panels,omitempty" toml:"panel,omitempty"`
+	FieldConfig     *FieldConfig               `json:"fieldConfig,omitempty" toml:"fieldConfig,omitempty"`
+	Options         interface{}                `json:"options,omitempty" toml:"-"`
+	Extra           map[string]json.RawMessage `json:"-" toml:"-"`
+	absent          map[string]bool
 }
 
 // A Target specify the metrics used by the Panel
 type Target struct {
-	Alias       string    `json:"alias"`
-	Hide        bool      `json:"hide"`
-	Measurement string    `json:"measurement"`
-	GroupBy     []GroupBy `json:"groupBy"`
-	Select      []Selects `json:"select,omitempty"`
-	Tags        []Tag     `json:"tags"`
-	DsType      string    `json:"dsType,omitempty"`
-	Transform   string    `json:"transform,omitempty" toml:"transform,omitempty"`
+	Alias       string                     `json:"alias"`
+	Hide        bool                       `json:"hide"`
+	Measurement string                     `json:"measurement"`
+	GroupBy     []GroupBy                  `json:"groupBy"`
+	Select      []Selects                  `json:"select,omitempty"`
+	Tags        []Tag                      `json:"tags"`
+	DsType      string                     `json:"dsType,omitempty"`
+	Transform   string                     `json:"transform,omitempty" toml:"transform,omitempty"`
+	Extra       map[string]json.RawMessage `json:"-" toml:"-"`
+	absent      map[string]bool
 }
 
 // Selects array of Select struct
@@ -298,21 +330,25 @@ type Select struct {
 
 // A Legend specify the legend options used by the Panel
 type Legend struct {
-	Show         bool `json:"show"`
-	Values       bool `json:"values"`
-	Min          bool `json:"min"`
-	Max          bool `json:"max"`
-	Current      bool `json:"current"`
-	Total        bool `json:"total"`
-	Avg          bool `json:"avg"`
-	AlignAsTable bool `json:"alignAsTable"`
+	Show         bool                       `json:"show"`
+	Values       bool                       `json:"values"`
+	Min          bool                       `json:"min"`
+	Max          bool                       `json:"max"`
+	Current      bool                       `json:"current"`
+	Total        bool                       `json:"total"`
+	Avg          bool                       `json:"avg"`
+	AlignAsTable bool                       `json:"alignAsTable"`
+	Extra        map[string]json.RawMessage `json:"-" toml:"-"`
+	absent       map[string]bool
 }
 
 // A GroupBy struct is used to setup the group by part of the query
 type GroupBy struct {
-	Type     string   `json:"type"`
-	Interval string   `json:"interval,omitempty"`
-	Params   []string `json:"params"`
+	Type     string                     `json:"type"`
+	Interval string                     `json:"interval,omitempty"`
+	Params   []string                   `json:"params"`
+	Extra    map[string]json.RawMessage `json:"-" toml:"-"`
+	absent   map[string]bool
 }
 
 //NewGroupBy initialize a GroupBy structure
@@ -342,23 +378,30 @@ type Metric struct {
 }
 
 // A SeriesOverride allows to setup specific override by serie
+// Stack is a boolean, or the name of a stack group like "A".
 type SeriesOverride struct {
-	Alias     string `json:"alias"`
-	Stack     bool   `json:"stack"`
-	Fill      int    `json:"fill"`
-	Transform string `json:"transform"`
+	Alias     string                     `json:"alias"`
+	Stack     interface{}                `json:"stack"`
+	Fill      int                        `json:"fill"`
+	Transform string                     `json:"transform"`
+	Extra     map[string]json.RawMessage `json:"-" toml:"-"`
+	absent    map[string]bool
 }
 
 // A Tag allows to filter the values
 type Tag struct {
-	Condition string `json:"condition"`
-	Key       string `json:"key"`
-	Value     string `json:"value"`
+	Condition string                     `json:"condition"`
+	Key       string                     `json:"key"`
+	Value     string                     `json:"value"`
+	Extra     map[string]json.RawMessage `json:"-" toml:"-"`
+	absent    map[string]bool
 }
 
 // A Tooltip allow to setup some graphic display options
 type Tooltip struct {
-	ValueType string `json:"value_type" toml:"value_type"`
+	ValueType string                     `json:"value_type" toml:"value_type"`
+	Extra     map[string]json.RawMessage `json:"-" toml:"-"`
+	absent    map[string]bool
 }
 
 // NewRow create a new Grafana row with default values
@@ -369,7 +412,7 @@ func NewRow() Row {
 // NewPanel create a new Grafana panel with default values
 func NewPanel() Panel {
 	return Panel{Span: 6,
-		Type:          "graph",
+		Type:          PanelGraph,
 		Editable:      true,
 		Fill:          0,
 		Legend:        NewLegend(),
@@ -382,6 +425,26 @@ func NewTarget() Target {
 	return Target{Alias: "$tag_host $tag_name", DsType: "influxdb"}
 }
 
+// NewMetricTarget create a new Grafana target from a Metric.
+// The target filters the measurement on the metric hosts and fields and groups by both tags.
+func NewMetricTarget(metric Metric) Target {
+	target := NewTarget()
+	fields := strings.Join(metric.Fields, "|")
+	hosts := strings.Join(metric.Hosts, "|")
+
+	target.Measurement = metric.Measurement
+
+	// adding tags
+	hostTag := Tag{Key: "host", Value: "/" + hosts + "/"}
+	target.Tags = append(target.Tags, hostTag)
+	fieldsTag := Tag{Key: "name", Value: "/" + fields + "/", Condition: "AND"}
+	target.Tags = append(target.Tags, fieldsTag)
+	target.GroupBy = NewGroupBy()
+	target.GroupBy = append(target.GroupBy, GroupBy{Type: "tag", Params: []string{"name"}})
+	target.GroupBy = append(target.GroupBy, GroupBy{Type: "tag", Params: []string{"host"}})
+	return target
+}
+
 // NewLegend create a new Grafana legend with default values
 func NewLegend() Legend {
 	return Legend{Show: true}
@@ -405,116 +468,265 @@ func NewTemplate() Template {
 // NewSession creates a new http connection .
 // It includes a cookie jar used to keep session cookies.
 // The URL url specifies the host and request URI.
+// Server certificates are verified, use NewSessionWithOptions to change the TLS settings.
 //
 // It returns a Session struct pointer.
 func NewSession(user string, password string, url string) *Session {
-	jar, err := cookiejar.New(nil)
+	session, _ := NewSessionWithOptions(url, WithCredentials(user, password))
+	return session
+}
+
+// NewTokenSession creates a new http connection authenticated with a Grafana API key
+// or service account token.
+// The token is sent as a bearer token on every request, DoLogon is not needed.
+//
+// It returns a Session struct pointer.
+func NewTokenSession(token string, url string) *Session {
+	session, _ := NewSessionWithOptions(url, WithToken(token))
+	return session
+}
+
+// NewBasicAuthSession creates a new http connection using HTTP basic authentication.
+// The credentials are sent on every request, DoLogon is not needed.
+//
+// It returns a Session struct pointer.
+func NewBasicAuthSession(user string, password string, url string) *Session {
+	session, _ := NewSessionWithOptions(url, WithBasicAuth(user, password))
+	return session
+}
+
+// httpRequest handle the request to Grafana server.
+// The response body is always read entirely and closed so the connection can be reused.
+// Requests are throttled by the rate limiter and retried according to the retry policy of the Session.
+//It returns the response body and a error if something went wrong
+// If the context is canceled or its deadline is exceeded, the context error is returned.
+func (s *Session) httpRequest(ctx context.Context, method string, url string, body []byte) (result []byte, err error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		log.Fatal(err)
+		return result, GrafanaError{Description: "Unable to create the http request", Method: method, URL: url, Err: err}
 	}
+	request.Header.Set("Content-Type", "application/json")
 
-	client := http.Client{Jar: jar, Timeout: time.Second * timeout}
+	switch {
+	case s.Token != "":
+		request.Header.Set("Authorization", "Bearer "+s.Token)
+	case s.basicAuth:
+		request.SetBasicAuth(s.User, s.Password)
+	}
+	if s.orgID != 0 {
+		request.Header.Set("X-Grafana-Org-Id", strconv.Itoa(s.orgID))
+	}
 
-	if protocolRegexp.MatchString(url) {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	for attempt := 1; ; attempt++ {
+		if s.limiter != nil {
+			if waitErr := s.limiter.wait(ctx); waitErr != nil {
+				return nil, GrafanaError{Description: "Request canceled", Method: method, URL: url, Err: waitErr}
+			}
 		}
-		client.Transport = tr
-	}
 
-	return &Session{client: &client, User: user, Password: password, url: url}
+		var response *http.Response
+		result, response, err = s.sendRequest(request)
+
+		delay, retry := s.retry.backoff(method, attempt, response, err)
+		if !retry {
+			return
+		}
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return nil, GrafanaError{Description: "Request canceled", Method: method, URL: url, Err: sleepErr}
+		}
+	}
 }
 
-// httpRequest handle the request to Grafana server.
-//It returns the response body and a error if something went wrong
-func (s *Session) httpRequest(method string, url string, body io.Reader) (result io.Reader, err error) {
-	request, err := http.NewRequest(method, url, body)
-	request.Header.Set("Content-Type", "application/json")
+// sendRequest performs a single attempt of the http request to Grafana server.
+// The returned response can only be used to inspect the status and headers, its body is already closed.
+func (s *Session) sendRequest(request *http.Request) (result []byte, response *http.Response, err error) {
+	ctx := request.Context()
+	method := request.Method
+	url := request.URL.String()
 
-	response, err := s.client.Do(request)
+	attempt := request.Clone(ctx)
+	if request.GetBody != nil {
+		if attempt.Body, err = request.GetBody(); err != nil {
+			return result, nil, GrafanaError{Description: "Unable to create the http request", Method: method, URL: url, Err: err}
+		}
+	}
+
+	response, err = s.client.Do(attempt)
 	if err != nil {
-		return result, GrafanaError{0, "Unable to perform the http request"}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return result, nil, GrafanaError{Description: "Request canceled", Method: method, URL: url, Err: ctxErr}
+		}
+		return result, nil, GrafanaError{Description: "Unable to perform the http request", Method: method, URL: url, Err: err}
 	}
 
-	//	defer response.Body.Close()
-	if response.StatusCode != 200 {
-		dec := json.NewDecoder(response.Body)
-		var gMess GrafanaMessage
-		dec.Decode(&gMess)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return result, response, newResponseError(method, url, response)
+	}
 
-		return result, GrafanaError{response.StatusCode, gMess.Message}
+	defer response.Body.Close()
+	result, err = ioutil.ReadAll(response.Body)
+	if err != nil {
+		return result, response, GrafanaError{Code: response.StatusCode, Description: "Unable to read the http response", Method: method, URL: url, Err: err}
+	}
+	return
+}
+
+// doJSON performs a request to Grafana server with in encoded as json body and
+// decodes the json response into out.
+// in and out are ignored when nil.
+func (s *Session) doJSON(ctx context.Context, method string, url string, in interface{}, out interface{}) (err error) {
+	var body []byte
+	if in != nil {
+		body, err = json.Marshal(in)
+		if err != nil {
+			return GrafanaError{Description: "Unable to encode the request", Method: method, URL: url, Err: err}
+		}
+	}
+
+	result, err := s.httpRequest(ctx, method, url, body)
+	if err != nil || out == nil {
+		return
+	}
+
+	if err = json.Unmarshal(result, out); err != nil {
+		return GrafanaError{Description: "Unable to decode the response", Method: method, URL: url, Body: result, Err: err}
 	}
-	result = response.Body
 	return
 }
 
 // DoLogon uses  a new http connection using the credentials stored in the Session struct.
 // It returns a error if it cannot perform the login.
 func (s *Session) DoLogon() (err error) {
+	return s.DoLogonContext(context.Background())
+}
+
+// DoLogonContext is like DoLogon but uses the provided context for the request.
+func (s *Session) DoLogonContext(ctx context.Context) (err error) {
 	reqURL := s.url + "/login"
 
 	login := Login{User: s.User, Password: s.Password}
-	jsonStr, _ := json.Marshal(login)
+	return s.doJSON(ctx, "POST", reqURL, login, nil)
+}
 
-	_, err = s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+// A dataSourceResult contains the response from Grafana when saving a DataSource.
+// Older Grafana versions only return the ID.
+type dataSourceResult struct {
+	ID         int         `json:"id"`
+	DataSource *DataSource `json:"datasource"`
+}
 
-	return
+// saved returns the DataSource stored by Grafana, or the sent one with the assigned ID
+// when the server doesn't return it.
+func (r dataSourceResult) saved(ds DataSource) DataSource {
+	if r.DataSource != nil {
+		return *r.DataSource
+	}
+	if r.ID != 0 {
+		ds.ID = r.ID
+	}
+	return ds
 }
 
 // CreateDataSource creates a Grafana DataSource.
 // It take a DataSource struct in parameter.
+// It returns the created DataSource with its assigned ID.
 // It returns a error if it cannot perform the creation.
-func (s *Session) CreateDataSource(ds DataSource) (err error) {
+func (s *Session) CreateDataSource(ds DataSource) (DataSource, error) {
+	return s.CreateDataSourceContext(context.Background(), ds)
+}
+
+// CreateDataSourceContext is like CreateDataSource but uses the provided context for the request.
+func (s *Session) CreateDataSourceContext(ctx context.Context, ds DataSource) (DataSource, error) {
 	reqURL := s.url + "/api/datasources"
 
-	jsonStr, _ := json.Marshal(ds)
-	_, err = s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	var result dataSourceResult
+	if err := s.doJSON(ctx, "POST", reqURL, ds, &result); err != nil {
+		return ds, err
+	}
+	return result.saved(ds), nil
+}
 
-	return
+// UpdateDataSource updates a existing Grafana DataSource identified by its ID.
+// It returns the updated DataSource.
+// It returns a error if it cannot perform the update.
+func (s *Session) UpdateDataSource(ds DataSource) (DataSource, error) {
+	return s.UpdateDataSourceContext(context.Background(), ds)
+}
+
+// UpdateDataSourceContext is like UpdateDataSource but uses the provided context for the request.
+func (s *Session) UpdateDataSourceContext(ctx context.Context, ds DataSource) (DataSource, error) {
+	reqURL := fmt.Sprintf("%s/api/datasources/%d", s.url, ds.ID)
+
+	var result dataSourceResult
+	if err := s.doJSON(ctx, "PUT", reqURL, ds, &result); err != nil {
+		return ds, err
+	}
+	return result.saved(ds), nil
 }
 
 // DeleteDataSource deletes a Grafana DataSource.
 // It take a existing DataSource struct in parameter.
 // It returns a error if it cannot perform the deletion.
 func (s *Session) DeleteDataSource(ds DataSource) (err error) {
+	return s.DeleteDataSourceContext(context.Background(), ds)
+}
 
+// DeleteDataSourceContext is like DeleteDataSource but uses the provided context for the request.
+func (s *Session) DeleteDataSourceContext(ctx context.Context, ds DataSource) (err error) {
 	reqURL := fmt.Sprintf("%s/api/datasources/%d", s.url, ds.ID)
 
-	jsonStr, _ := json.Marshal(ds)
-	_, err = s.httpRequest("DELETE", reqURL, bytes.NewBuffer(jsonStr))
-
-	return
+	return s.doJSON(ctx, "DELETE", reqURL, nil, nil)
 }
 
 // GetDataSourcePlugins return a list of existing Grafana DataSources.
 // It return a array of DataSource struct.
+// Since Grafana 3.0, the datasource plugins are listed with the plugins API
+// and only their name and type are set.
 // It returns a error if it cannot get the DataSource list.
 func (s *Session) GetDataSourcePlugins() (plugins DataSourcePlugins, err error) {
-	reqURL := s.url + "/api/datasources/plugins"
-
-	body, err := s.httpRequest("GET", reqURL, nil)
+	return s.GetDataSourcePluginsContext(context.Background())
+}
 
+// GetDataSourcePluginsContext is like GetDataSourcePlugins but uses the provided context for the requests.
+func (s *Session) GetDataSourcePluginsContext(ctx context.Context) (plugins DataSourcePlugins, err error) {
+	v, err := s.requireVersion(ctx, minVersion.Major, minVersion.Minor, "GetDataSourcePlugins")
 	if err != nil {
 		return
 	}
+	if !v.AtLeast(3, 0) {
+		reqURL := s.url + "/api/datasources/plugins"
+		err = s.doJSON(ctx, "GET", reqURL, nil, &plugins)
+		return
+	}
 
-	dec := json.NewDecoder(body)
-	err = dec.Decode(&plugins)
+	list, err := s.GetPluginsContext(ctx, "datasource")
+	if err != nil {
+		return
+	}
+	plugins = make(DataSourcePlugins)
+	for _, plugin := range list {
+		plugins[plugin.ID] = DataSourcePlugin{Name: plugin.Name, PluginType: plugin.Type, Type: plugin.ID}
+	}
 	return
 }
 
 //GetPlugins get the list of plugins by PluginType
+// It returns a error matching ErrUnsupportedVersion with Grafana servers older than 3.0.
 func (s *Session) GetPlugins(pluginType string) (plugins Plugins, err error) {
-	reqURL := s.url + "/api/plugins?type=" + pluginType
-
-	body, err := s.httpRequest("GET", reqURL, nil)
+	return s.GetPluginsContext(context.Background(), pluginType)
+}
 
-	if err != nil {
+// GetPluginsContext is like GetPlugins but uses the provided context for the request.
+func (s *Session) GetPluginsContext(ctx context.Context, pluginType string) (plugins Plugins, err error) {
+	if _, err = s.requireVersion(ctx, 3, 0, "GetPlugins"); err != nil {
 		return
 	}
-
-	dec := json.NewDecoder(body)
-	err = dec.Decode(&plugins)
+	reqURL := s.url + "/api/plugins?type=" + neturl.QueryEscape(pluginType)
+	err = s.doJSON(ctx, "GET", reqURL, nil, &plugins)
 	return
 }
 
@@ -522,47 +734,92 @@ func (s *Session) GetPlugins(pluginType string) (plugins Plugins, err error) {
 // It return a array of DataSource struct.
 // It returns a error if it cannot get the DataSource list.
 func (s *Session) GetDataSourceList() (ds []DataSource, err error) {
-	reqURL := s.url + "/api/datasources"
+	return s.GetDataSourceListContext(context.Background())
+}
 
-	body, err := s.httpRequest("GET", reqURL, nil)
-	if err != nil {
-		return
-	}
-	dec := json.NewDecoder(body)
-	err = dec.Decode(&ds)
+// GetDataSourceListContext is like GetDataSourceList but uses the provided context for the request.
+func (s *Session) GetDataSourceListContext(ctx context.Context) (ds []DataSource, err error) {
+	reqURL := s.url + "/api/datasources"
+	err = s.doJSON(ctx, "GET", reqURL, nil, &ds)
 	return
 }
 
 // GetDataSource get a existing DataSource by name.
 // It return a DataSource struct.
+// It returns a error matching ErrNotFound if the DataSource doesn't exist.
 // It returns a error if a problem occurs when trying to retrieve the DataSource.
 func (s *Session) GetDataSource(name string) (ds DataSource, err error) {
-	dslist, err := s.GetDataSourceList()
-	if err != nil {
-		return
-	}
+	return s.GetDataSourceContext(context.Background(), name)
+}
 
-	for _, elem := range dslist {
-		if elem.Name == name {
-			ds = elem
-		}
-	}
+// GetDataSourceContext is like GetDataSource but uses the provided context for the request.
+func (s *Session) GetDataSourceContext(ctx context.Context, name string) (ds DataSource, err error) {
+	return s.GetDataSourceByNameContext(ctx, name)
+}
+
+// GetDataSourceByID get a existing DataSource by ID.
+// It returns a error matching ErrNotFound if the DataSource doesn't exist.
+func (s *Session) GetDataSourceByID(id int) (ds DataSource, err error) {
+	return s.GetDataSourceByIDContext(context.Background(), id)
+}
+
+// GetDataSourceByIDContext is like GetDataSourceByID but uses the provided context for the request.
+func (s *Session) GetDataSourceByIDContext(ctx context.Context, id int) (ds DataSource, err error) {
+	reqURL := fmt.Sprintf("%s/api/datasources/%d", s.url, id)
+	err = s.doJSON(ctx, "GET", reqURL, nil, &ds)
+	return
+}
+
+// GetDataSourceByUID get a existing DataSource by UID.
+// It returns a error matching ErrNotFound if the DataSource doesn't exist.
+func (s *Session) GetDataSourceByUID(uid string) (ds DataSource, err error) {
+	return s.GetDataSourceByUIDContext(context.Background(), uid)
+}
+
+// GetDataSourceByUIDContext is like GetDataSourceByUID but uses the provided context for the request.
+func (s *Session) GetDataSourceByUIDContext(ctx context.Context, uid string) (ds DataSource, err error) {
+	reqURL := s.url + "/api/datasources/uid/" + neturl.PathEscape(uid)
+	err = s.doJSON(ctx, "GET", reqURL, nil, &ds)
+	return
+}
+
+// GetDataSourceByName get a existing DataSource by name.
+// It returns a error matching ErrNotFound if the DataSource doesn't exist.
+func (s *Session) GetDataSourceByName(name string) (ds DataSource, err error) {
+	return s.GetDataSourceByNameContext(context.Background(), name)
+}
+
+// GetDataSourceByNameContext is like GetDataSourceByName but uses the provided context for the request.
+func (s *Session) GetDataSourceByNameContext(ctx context.Context, name string) (ds DataSource, err error) {
+	reqURL := s.url + "/api/datasources/name/" + neturl.PathEscape(name)
+	err = s.doJSON(ctx, "GET", reqURL, nil, &ds)
 	return
 }
 
 // GetDashboard get a existing Dashboard by name.
-// It takes a name string in parameter.
-// It return a bytes.Buffer pointer.
-// It returns a error if a problem occurs when trying to retrieve the DataSource.
+// It takes a name string in parameter, the dashboard slug or title.
+// The dashboard is searched and retrieved by UID. The slug API is only used
+// with Grafana servers older than 5.0, which don't know dashboard UIDs.
+// It returns a error if a problem occurs when trying to retrieve the Dashboard.
 func (s *Session) GetDashboard(name string) (dashboard DashboardResult, err error) {
-	reqURL := s.url + "/api/dashboards/db/" + name
-	body, err := s.httpRequest("GET", reqURL, nil)
+	return s.GetDashboardContext(context.Background(), name)
+}
+
+// GetDashboardContext is like GetDashboard but uses the provided context for the request.
+func (s *Session) GetDashboardContext(ctx context.Context, name string) (dashboard DashboardResult, err error) {
+	v, err := s.requireVersion(ctx, minVersion.Major, minVersion.Minor, "GetDashboard")
 	if err != nil {
 		return
 	}
-	dec := json.NewDecoder(body)
-	err = dec.Decode(&dashboard)
-	return
+	if !v.AtLeast(5, 0) {
+		return s.getDashboardBySlug(ctx, name)
+	}
+
+	uid, err := s.findDashboardUID(ctx, name)
+	if err != nil {
+		return
+	}
+	return s.GetDashboardByUIDContext(ctx, uid)
 }
 
 // AddRow add a row to an existing dashboard.
@@ -571,6 +828,12 @@ func (db *Dashboard) AddRow(row Row) {
 	db.Rows = append(db.Rows, row)
 }
 
+// AddPanel add a panel to the grid layout of an existing dashboard.
+// It takes a Panel struct in parameter.
+func (db *Dashboard) AddPanel(panel Panel) {
+	db.Panels = append(db.Panels, panel)
+}
+
 // SetTimeFrame setup the dashboard timeframe.
 func (db *Dashboard) SetTimeFrame(from time.Time, to time.Time) {
 	db.GTime = GTime{From: from.Format(time.RFC3339), To: to.Format(time.RFC3339)}
@@ -608,48 +871,76 @@ func (target *Target) GroupByTag(tag string) {
 // This string will be decoded against a Dashboard struct for validation.
 // If valid, the dashboard structure will be sent to UploadDashboard.
 // overwrite parameter define if it overwrite existing dashboard.
+// It returns the save result like UploadDashboard.
 // It returns a error if a problem occurs when trying to create the dashboard.
-func (s *Session) UploadDashboardString(dashboard string, overwrite bool) (err error) {
+func (s *Session) UploadDashboardString(dashboard string, overwrite bool) (DashboardSaveResult, error) {
+	return s.UploadDashboardStringContext(context.Background(), dashboard, overwrite)
+}
+
+// UploadDashboardStringContext is like UploadDashboardString but uses the provided context for the request.
+func (s *Session) UploadDashboardStringContext(ctx context.Context, dashboard string, overwrite bool) (DashboardSaveResult, error) {
 	dec := json.NewDecoder(bytes.NewBuffer([]byte(dashboard)))
 	var ds Dashboard
-	err = dec.Decode(&ds)
-	if err != nil {
-		return GrafanaError{0, "dashboard template in wrong format"}
+	if err := dec.Decode(&ds); err != nil {
+		return DashboardSaveResult{}, GrafanaError{Description: "dashboard template in wrong format", Err: err}
 	}
-	err = s.UploadDashboard(ds, overwrite)
-	return
+	return s.UploadDashboardContext(ctx, ds, overwrite)
 }
 
 // UploadDashboard upload a new Dashboard.
 // It takes a dashboard structure in parameter.
 // It encapsulate it in a DashboardUploader structure.
 // overwrite parameter define if it overwrite existing dashboard.
+// A dashboard with a UID is created or updated with this UID.
+// It returns the save result with the dashboard ID, UID, URL and version.
 // It returns a error if a problem occurs when creating the dashboard.
-func (s *Session) UploadDashboard(dashboard Dashboard, overwrite bool) (err error) {
-	reqURL := s.url + "/api/dashboards/db"
+func (s *Session) UploadDashboard(dashboard Dashboard, overwrite bool) (DashboardSaveResult, error) {
+	return s.UploadDashboardContext(context.Background(), dashboard, overwrite)
+}
 
+// UploadDashboardContext is like UploadDashboard but uses the provided context for the request.
+func (s *Session) UploadDashboardContext(ctx context.Context, dashboard Dashboard, overwrite bool) (result DashboardSaveResult, err error) {
 	var content DashboardUploader
 	content.Dashboard = dashboard
 	content.Overwrite = overwrite
-	jsonStr, _ := json.Marshal(content)
+	return s.SaveDashboardContext(ctx, content)
+}
+
+// SaveDashboard upload a Dashboard with the folder and message set in the DashboardUploader.
+// It returns the save result with the dashboard ID, UID, URL and version.
+// It returns a error if a problem occurs when saving the dashboard.
+func (s *Session) SaveDashboard(content DashboardUploader) (DashboardSaveResult, error) {
+	return s.SaveDashboardContext(context.Background(), content)
+}
 
-	_, err = s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+// SaveDashboardContext is like SaveDashboard but uses the provided context for the request.
+func (s *Session) SaveDashboardContext(ctx context.Context, content DashboardUploader) (result DashboardSaveResult, err error) {
+	reqURL := s.url + "/api/dashboards/db"
+	err = s.doJSON(ctx, "POST", reqURL, content, &result)
 	return
 }
 
 //DeleteDashboard delete a Grafana Dashboard.
-// First, it try to retrieve it. And if successful, delete it using the slug attribute
+// First, it try to retrieve it. And if successful, delete it using its UID,
+// or the slug attribute with Grafana servers older than 5.0.
 // It returns a error if a problem occurs when deleting the dashboard.
 func (s *Session) DeleteDashboard(name string) (err error) {
-	dashRes, err := s.GetDashboard(name)
+	return s.DeleteDashboardContext(context.Background(), name)
+}
+
+// DeleteDashboardContext is like DeleteDashboard but uses the provided context for the request.
+func (s *Session) DeleteDashboardContext(ctx context.Context, name string) (err error) {
+	dashRes, err := s.GetDashboardContext(ctx, name)
 	if err != nil {
 		return
 	}
 
+	if uid := dashRes.UID(); uid != "" {
+		return s.DeleteDashboardByUIDContext(ctx, uid)
+	}
 	slug := dashRes.Meta.Slug
 	reqURL := fmt.Sprintf("%s/api/dashboards/db/%s", s.url, slug)
-	_, err = s.httpRequest("DELETE", reqURL, nil)
-	return
+	return s.doJSON(ctx, "DELETE", reqURL, nil, nil)
 }
 
 //ConvertTemplate converts a string to a dashboard structure
@@ -699,21 +990,7 @@ func ConvertTemplate(file string) (dashboard Dashboard, err error) {
 				panic(err)
 			}
 			for _, metric := range panel.Metrics {
-				target := NewTarget()
-				fields := strings.Join(metric.Fields, "|")
-				hosts := strings.Join(metric.Hosts, "|")
-
-				target.Measurement = metric.Measurement
-
-				// adding tags
-				hostTag := Tag{Key: "host", Value: "/" + hosts + "/"}
-				target.Tags = append(target.Tags, hostTag)
-				fieldsTag := Tag{Key: "name", Value: "/" + fields + "/", Condition: "AND"}
-				target.Tags = append(target.Tags, fieldsTag)
-				target.GroupBy = NewGroupBy()
-				target.GroupBy = append(target.GroupBy, GroupBy{Type: "tag", Params: []string{"name"}})
-				target.GroupBy = append(target.GroupBy, GroupBy{Type: "tag", Params: []string{"host"}})
-				panel.Targets = append(panel.Targets, target)
+				panel.Targets = append(panel.Targets, NewMetricTarget(metric))
 			}
 		}
 	}
@@ -1,6 +1,12 @@
 package grafanaclient
 
 import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -52,7 +58,7 @@ func Test_CreateDataSource(t *testing.T) {
 	session := NewSession(user, pass, url)
 	err := session.DoLogon()
 	assert.Nil(t, err, "We are expecting no error and got one")
-	err = session.CreateDataSource(ds)
+	_, err = session.CreateDataSource(ds)
 	assert.Nil(t, err, "We are expecting no error and got one when creating DataSource")
 }
 
@@ -87,7 +93,7 @@ func Test_CreateDashboard(t *testing.T) {
 	err := session.DoLogon()
 	assert.Nil(t, err, "We are expecting no error and got one when Login")
 
-	err = session.UploadDashboardString(dashboard, true)
+	_, err = session.UploadDashboardString(dashboard, true)
 	assert.Nil(t, err, "We are expecting no error and got one when Uploading")
 }
 
@@ -120,7 +126,7 @@ func Test_UploadDasboardFromTemplate(t *testing.T) {
 	dashboard, err := ConvertTemplate("example.toml")
 	assert.Nil(t, err, "We are expecting no error and got one when Converting template")
 	assert.NotNil(t, dashboard, "We are expecting to receive a dashboard")
-	err = session.UploadDashboard(dashboard, true)
+	_, err = session.UploadDashboard(dashboard, true)
 	assert.Nil(t, err, "We are expecting no error and got one when Uploading")
 }
 
@@ -143,3 +149,143 @@ func Test_DeleteDashboard(t *testing.T) {
 	err = session.DeleteDashboard("new-dashboard")
 	assert.Nil(t, err, "We are expecting no error and got one when Deleting")
 }
+
+func Test_TokenSession(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	session := NewTokenSession("secret", srv.URL)
+	_, err := session.GetDataSourceList()
+	assert.Nil(t, err, "We are expecting no error and got one getting DataSource")
+	assert.Equal(t, "Bearer secret", auth, "We are expecting a bearer token in the Authorization header")
+}
+
+func Test_BasicAuthSession(t *testing.T) {
+	var reqUser, reqPass string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqUser, reqPass, _ = r.BasicAuth()
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	session := NewBasicAuthSession(user, pass, srv.URL)
+	_, err := session.GetDataSourceList()
+	assert.Nil(t, err, "We are expecting no error and got one getting DataSource")
+	assert.Equal(t, user, reqUser, "We are expecting the basic auth user to be sent")
+	assert.Equal(t, pass, reqPass, "We are expecting the basic auth password to be sent")
+}
+
+func Test_ContextCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	session := NewTokenSession("secret", srv.URL)
+	_, err := session.GetDataSourceListContext(ctx)
+	assert.True(t, errors.Is(err, context.Canceled), "We are expecting a context cancellation error")
+}
+
+func Test_NoConnectionLeak(t *testing.T) {
+	var conns int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "DELETE":
+			w.Write([]byte(`{"message":"Data source deleted"}`))
+		case "POST":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"message":"Data source with same name already exists"}`))
+		default:
+			w.Write([]byte(`[{"id":1,"name":"testme"}]`))
+		}
+	}))
+	srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	session := NewTokenSession("secret", srv.URL)
+	for i := 0; i < 50; i++ {
+		err := session.DeleteDataSource(ds)
+		assert.Nil(t, err, "We are expecting no error and got one when Deleting")
+		_, err = session.CreateDataSource(ds)
+		assert.True(t, errors.Is(err, ErrConflict), "We are expecting a conflict error")
+		_, err = session.GetDataSourceList()
+		assert.Nil(t, err, "We are expecting no error and got one getting DataSource")
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&conns), "We are expecting a single reused connection")
+}
+
+func newDataSourceServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/datasources", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":12,"message":"Datasource added","name":"testme"}`))
+	})
+	mux.HandleFunc("/api/datasources/12", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			w.Write([]byte(`{"datasource":{"id":12,"uid":"abc","name":"testme","url":"http://influx:8086"},"message":"Datasource updated"}`))
+			return
+		}
+		w.Write([]byte(`{"id":12,"uid":"abc","name":"testme"}`))
+	})
+	mux.HandleFunc("/api/datasources/uid/abc", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":12,"uid":"abc","name":"testme"}`))
+	})
+	mux.HandleFunc("/api/datasources/name/testme", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":12,"uid":"abc","name":"testme"}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Data source not found"}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func Test_CreateAndUpdateDataSourceResult(t *testing.T) {
+	srv := newDataSourceServer()
+	defer srv.Close()
+
+	session := NewTokenSession("secret", srv.URL)
+	created, err := session.CreateDataSource(ds)
+	assert.Nil(t, err, "We are expecting no error and got one when creating DataSource")
+	assert.Equal(t, 12, created.ID, "We are expecting the assigned DataSource ID")
+	assert.Equal(t, "testme", created.Name)
+
+	created.URL = "http://influx:8086"
+	updated, err := session.UpdateDataSource(created)
+	assert.Nil(t, err, "We are expecting no error and got one when updating DataSource")
+	assert.Equal(t, "abc", updated.UID)
+	assert.Equal(t, "http://influx:8086", updated.URL)
+}
+
+func Test_DataSourceLookups(t *testing.T) {
+	srv := newDataSourceServer()
+	defer srv.Close()
+
+	session := NewTokenSession("secret", srv.URL)
+
+	byID, err := session.GetDataSourceByID(12)
+	assert.Nil(t, err, "We are expecting no error and got one getting DataSource by ID")
+	byUID, err := session.GetDataSourceByUID("abc")
+	assert.Nil(t, err, "We are expecting no error and got one getting DataSource by UID")
+	byName, err := session.GetDataSource("testme")
+	assert.Nil(t, err, "We are expecting no error and got one getting DataSource by name")
+	assert.Equal(t, byID, byUID)
+	assert.Equal(t, byID, byName)
+
+	_, err = session.GetDataSource("missing")
+	assert.True(t, errors.Is(err, ErrNotFound), "We are expecting a not found error")
+	_, err = session.GetDataSourceByUID("missing")
+	assert.True(t, errors.Is(err, ErrNotFound), "We are expecting a not found error")
+}
@@ -0,0 +1,250 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"fmt"
+)
+
+// maxRowSpan is the width of a row, in spans.
+const maxRowSpan = 12
+
+// A DashboardBuilder builds a Dashboard with chained calls, like:
+//
+//	dashboard, err := NewDashboardBuilder("nmon").
+//		Tags("aix").
+//		DataSource("nmon").
+//		Row("CPU").
+//		Graph("CPU usage", 8, NewMetricTarget(cpu)).
+//		Panel(NewStatPanel("CPU load", NewStatOptions()), 4).
+//		Row("Memory").
+//		Graph("Memory", 12, NewMetricTarget(memory)).
+//		Build()
+//
+// The panels get unique IDs, in the order they are added, when Build is called.
+// The first invalid call is returned by Build, as a GrafanaError, and the following calls are ignored.
+type DashboardBuilder struct {
+	dashboard  Dashboard
+	dataSource string
+	spans      []int
+	err        error
+}
+
+// NewDashboardBuilder create a builder for a editable dashboard with the default time window.
+func NewDashboardBuilder(title string) *DashboardBuilder {
+	builder := &DashboardBuilder{}
+	builder.dashboard.Title = title
+	builder.dashboard.Editable = true
+	builder.dashboard.GTime = NewGTime()
+	if title == "" {
+		builder.fail("dashboard title is empty")
+	}
+	return builder
+}
+
+// fail records the first error, as a GrafanaError without HTTP code.
+func (b *DashboardBuilder) fail(format string, args ...interface{}) *DashboardBuilder {
+	if b.err == nil {
+		b.err = GrafanaError{Description: fmt.Sprintf(format, args...)}
+	}
+	return b
+}
+
+// Tags adds tags to the dashboard.
+func (b *DashboardBuilder) Tags(tags ...string) *DashboardBuilder {
+	if b.err == nil {
+		for _, tag := range tags {
+			b.dashboard.Tags = append(b.dashboard.Tags, tag)
+		}
+	}
+	return b
+}
+
+// Time sets the time window of the dashboard, like now-6h and now.
+func (b *DashboardBuilder) Time(from string, to string) *DashboardBuilder {
+	if b.err != nil {
+		return b
+	}
+	if from == "" || to == "" {
+		return b.fail("invalid time window from %q to %q", from, to)
+	}
+	b.dashboard.GTime = GTime{From: from, To: to}
+	return b
+}
+
+// DataSource sets the datasource of the panels which have neither a datasource name nor a datasource reference.
+func (b *DashboardBuilder) DataSource(name string) *DashboardBuilder {
+	if b.err == nil {
+		b.dataSource = name
+	}
+	return b
+}
+
+// Template adds a template variable. Its name must be unique.
+func (b *DashboardBuilder) Template(template Template) *DashboardBuilder {
+	if b.err != nil {
+		return b
+	}
+	if template.Name == "" {
+		return b.fail("template name is empty")
+	}
+	for _, defined := range b.dashboard.Templating.List {
+		if defined.Name == template.Name {
+			return b.fail("template %s is already defined", template.Name)
+		}
+	}
+	b.dashboard.Templating.List = append(b.dashboard.Templating.List, template)
+	return b
+}
+
+// Row starts a new row. The following panels are added to it.
+func (b *DashboardBuilder) Row(title string) *DashboardBuilder {
+	if b.err != nil {
+		return b
+	}
+	row := NewRow()
+	row.Title = title
+	b.dashboard.AddRow(row)
+	b.spans = append(b.spans, 0)
+	return b
+}
+
+// Height sets the height of the current row, like 250px.
+func (b *DashboardBuilder) Height(height string) *DashboardBuilder {
+	if b.err != nil {
+		return b
+	}
+	row := b.currentRow()
+	if row == nil {
+		return b.fail("row height %s set before any row", height)
+	}
+	row.Height = height
+	return b
+}
+
+// Collapse collapses the current row.
+func (b *DashboardBuilder) Collapse() *DashboardBuilder {
+	if b.err != nil {
+		return b
+	}
+	row := b.currentRow()
+	if row == nil {
+		return b.fail("collapse set before any row")
+	}
+	row.Collapse = true
+	return b
+}
+
+// Panel adds a panel to the current row with the given span, from 1 to 12.
+// The spans of the panels of a row cannot exceed 12.
+// A panel with a ID keeps it, the ID must be unique.
+func (b *DashboardBuilder) Panel(panel Panel, span int) *DashboardBuilder {
+	if b.err != nil {
+		return b
+	}
+	row := b.currentRow()
+	if row == nil {
+		return b.fail("panel %q added before any row", panel.Title)
+	}
+	if panel.Type == PanelRow {
+		return b.fail("row panel %q added to row %q, use Row", panel.Title, row.Title)
+	}
+	if span < 1 || span > maxRowSpan {
+		return b.fail("panel %q of row %q: span %d is not between 1 and %d", panel.Title, row.Title, span, maxRowSpan)
+	}
+	total := b.spans[len(b.spans)-1] + span
+	if total > maxRowSpan {
+		return b.fail("panel %q of row %q: row span %d is over %d", panel.Title, row.Title, total, maxRowSpan)
+	}
+	if panel.ID != 0 && b.hasPanelID(panel.ID) {
+		return b.fail("panel %q of row %q: ID %d is already used", panel.Title, row.Title, panel.ID)
+	}
+
+	panel.Span = span
+	row.AddPanel(panel)
+	b.spans[len(b.spans)-1] = total
+	return b
+}
+
+// Graph adds a graph panel with the given targets to the current row.
+func (b *DashboardBuilder) Graph(title string, span int, targets ...Target) *DashboardBuilder {
+	panel := NewPanel()
+	panel.Title = title
+	panel.Targets = append(panel.Targets, targets...)
+	return b.Panel(panel, span)
+}
+
+// Target adds a target to the last added panel.
+func (b *DashboardBuilder) Target(target Target) *DashboardBuilder {
+	if b.err != nil {
+		return b
+	}
+	row := b.currentRow()
+	if row == nil || len(row.Panels) == 0 {
+		return b.fail("target %s added before any panel", target.Measurement)
+	}
+	row.Panels[len(row.Panels)-1].AddTarget(target)
+	return b
+}
+
+// Build returns the dashboard, or the first error of the chained calls.
+// The panels without ID get the lowest IDs not used by the other panels, in the order
+// they were added, so building the same expression always gives the same IDs.
+func (b *DashboardBuilder) Build() (Dashboard, error) {
+	if b.err != nil {
+		return Dashboard{}, b.err
+	}
+
+	dashboard := b.dashboard
+	dashboard.Rows = make([]Row, len(b.dashboard.Rows))
+	nextID := 1
+	for i, row := range b.dashboard.Rows {
+		row.Panels = append([]Panel(nil), row.Panels...)
+		for j := range row.Panels {
+			panel := &row.Panels[j]
+			if panel.ID == 0 {
+				for b.hasPanelID(nextID) {
+					nextID++
+				}
+				panel.ID = nextID
+				nextID++
+			}
+			if !isReference(panel.Extra["datasource"]) && panel.DataSource == "" {
+				panel.DataSource = b.dataSource
+			}
+		}
+		dashboard.Rows[i] = row
+	}
+	return dashboard, nil
+}
+
+// currentRow returns the last added row, or nil.
+func (b *DashboardBuilder) currentRow() *Row {
+	if len(b.dashboard.Rows) == 0 {
+		return nil
+	}
+	return &b.dashboard.Rows[len(b.dashboard.Rows)-1]
+}
+
+// hasPanelID reports if a added panel has the given ID.
+func (b *DashboardBuilder) hasPanelID(id int) bool {
+	for _, row := range b.dashboard.Rows {
+		for _, panel := range row.Panels {
+			if panel.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
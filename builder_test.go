@@ -0,0 +1,91 @@
+package grafanaclient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DashboardBuilder(t *testing.T) {
+	stat := NewStatPanel("CPU load", NewStatOptions())
+	stat.ID = 2
+	text := NewTextPanel("notes", TextOptions{Mode: "markdown", Content: "nmon"})
+	text.DataSource = "-- Grafana --"
+
+	dashboard, err := NewDashboardBuilder("nmon").
+		Tags("aix", "nmon").
+		Time("now-6h", "now").
+		DataSource("nmon").
+		Template(Template{Name: "host"}).
+		Row("CPU").
+		Graph("CPU usage", 8, NewTarget()).
+		Target(NewTarget()).
+		Panel(stat, 4).
+		Row("Memory").
+		Height("300px").
+		Collapse().
+		Graph("Memory", 6).
+		Panel(text, 6).
+		Build()
+	assert.Nil(t, err, "We are expecting no error and got one building dashboard")
+
+	assert.Equal(t, "nmon", dashboard.Title)
+	assert.Equal(t, []interface{}{"aix", "nmon"}, dashboard.Tags)
+	assert.Equal(t, GTime{From: "now-6h", To: "now"}, dashboard.GTime)
+	assert.Len(t, dashboard.Templating.List, 1)
+	assert.Len(t, dashboard.Rows, 2)
+	assert.Equal(t, "300px", dashboard.Rows[1].Height)
+	assert.True(t, dashboard.Rows[1].Collapse)
+
+	cpu := dashboard.Rows[0].Panels
+	assert.Equal(t, 1, cpu[0].ID)
+	assert.Equal(t, 8, cpu[0].Span)
+	assert.Len(t, cpu[0].Targets, 2)
+	assert.Equal(t, "nmon", cpu[0].DataSource)
+	assert.Equal(t, 2, cpu[1].ID, "We are expecting the panel ID to be kept")
+
+	memory := dashboard.Rows[1].Panels
+	assert.Equal(t, 3, memory[0].ID, "We are expecting the used IDs to be skipped")
+	assert.Equal(t, 4, memory[1].ID)
+	assert.Equal(t, "-- Grafana --", memory[1].DataSource)
+
+	again, _ := NewDashboardBuilder("nmon").
+		Row("CPU").
+		Graph("CPU usage", 8).
+		Panel(stat, 4).
+		Row("Memory").
+		Graph("Memory", 6).
+		Build()
+	assert.Equal(t, 3, again.Rows[1].Panels[0].ID, "We are expecting stable IDs")
+}
+
+func Test_DashboardBuilderErrors(t *testing.T) {
+	panel := NewPanel()
+	panel.ID = 1
+	builders := map[string]*DashboardBuilder{
+		"empty title":      NewDashboardBuilder(""),
+		"panel before row": NewDashboardBuilder("nmon").Graph("CPU", 6),
+		"span over 12":     NewDashboardBuilder("nmon").Row("CPU").Graph("CPU", 13),
+		"zero span":        NewDashboardBuilder("nmon").Row("CPU").Graph("CPU", 0),
+		"row span over 12": NewDashboardBuilder("nmon").Row("CPU").Graph("CPU", 8).Graph("load", 6),
+		"duplicate ID":     NewDashboardBuilder("nmon").Row("CPU").Panel(panel, 6).Row("Memory").Panel(panel, 6),
+		"row panel":        NewDashboardBuilder("nmon").Row("CPU").Panel(NewRowPanel("disk"), 6),
+		"target first":     NewDashboardBuilder("nmon").Row("CPU").Target(NewTarget()),
+		"duplicate var":    NewDashboardBuilder("nmon").Template(Template{Name: "host"}).Template(Template{Name: "host"}),
+		"unnamed var":      NewDashboardBuilder("nmon").Template(NewTemplate()),
+		"empty time":       NewDashboardBuilder("nmon").Time("", "now"),
+		"height first":     NewDashboardBuilder("nmon").Height("250px"),
+	}
+	for name, builder := range builders {
+		_, err := builder.Row("disk").Graph("disk", 6).Build()
+		assert.NotNil(t, err, "We are expecting a error for %s", name)
+	}
+
+	_, err := NewDashboardBuilder("nmon").Row("CPU").Graph("CPU", 14).Graph("load", 20).Build()
+	assert.Contains(t, err.Error(), "span 14", "We are expecting the first error")
+	var gErr GrafanaError
+	assert.True(t, errors.As(err, &gErr), "We are expecting a GrafanaError")
+	assert.Equal(t, 0, gErr.Code)
+	assert.Equal(t, `panel "CPU" of row "CPU": span 14 is not between 1 and 12`, gErr.Description)
+}
@@ -0,0 +1,142 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	neturl "net/url"
+	"path"
+	"strings"
+)
+
+// A DashboardSaveResult contains the response from Grafana when saving a Dashboard.
+type DashboardSaveResult struct {
+	ID      int    `json:"id"`
+	UID     string `json:"uid"`
+	URL     string `json:"url"`
+	Status  string `json:"status"`
+	Version int    `json:"version"`
+	Slug    string `json:"slug"`
+}
+
+// UnmarshalJSON decodes a DashboardResult sent by any Grafana version.
+func (r *DashboardResult) UnmarshalJSON(data []byte) error {
+	type plain DashboardResult
+	var content struct {
+		plain
+		Dashboard *Dashboard `json:"dashboard"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return err
+	}
+
+	*r = DashboardResult(content.plain)
+	if content.Dashboard != nil {
+		r.Model = *content.Dashboard
+	}
+	return nil
+}
+
+// UID returns the dashboard UID from the meta data or the model.
+// It returns an empty string with Grafana servers older than 5.0.
+func (r DashboardResult) UID() string {
+	if r.Meta.UID != "" {
+		return r.Meta.UID
+	}
+	return r.Model.UID
+}
+
+// GetDashboardByUID get a existing Dashboard by UID.
+// It returns a error matching ErrNotFound if the dashboard doesn't exist,
+// or ErrUnsupportedVersion with Grafana servers older than 5.0.
+func (s *Session) GetDashboardByUID(uid string) (dashboard DashboardResult, err error) {
+	return s.GetDashboardByUIDContext(context.Background(), uid)
+}
+
+// GetDashboardByUIDContext is like GetDashboardByUID but uses the provided context for the request.
+func (s *Session) GetDashboardByUIDContext(ctx context.Context, uid string) (dashboard DashboardResult, err error) {
+	if _, err = s.requireVersion(ctx, 5, 0, "GetDashboardByUID"); err != nil {
+		return
+	}
+	reqURL := s.url + "/api/dashboards/uid/" + neturl.PathEscape(uid)
+	err = s.doJSON(ctx, "GET", reqURL, nil, &dashboard)
+	return
+}
+
+// DeleteDashboardByUID delete a existing Dashboard by UID.
+// It returns a error matching ErrNotFound if the dashboard doesn't exist,
+// or ErrUnsupportedVersion with Grafana servers older than 5.0.
+func (s *Session) DeleteDashboardByUID(uid string) (err error) {
+	return s.DeleteDashboardByUIDContext(context.Background(), uid)
+}
+
+// DeleteDashboardByUIDContext is like DeleteDashboardByUID but uses the provided context for the request.
+func (s *Session) DeleteDashboardByUIDContext(ctx context.Context, uid string) (err error) {
+	if _, err = s.requireVersion(ctx, 5, 0, "DeleteDashboardByUID"); err != nil {
+		return
+	}
+	reqURL := s.url + "/api/dashboards/uid/" + neturl.PathEscape(uid)
+	return s.doJSON(ctx, "DELETE", reqURL, nil, nil)
+}
+
+// getDashboardBySlug get a existing Dashboard with the slug API removed in Grafana 8.
+func (s *Session) getDashboardBySlug(ctx context.Context, slug string) (dashboard DashboardResult, err error) {
+	reqURL := s.url + "/api/dashboards/db/" + slug
+	err = s.doJSON(ctx, "GET", reqURL, nil, &dashboard)
+	return
+}
+
+// findDashboardUID searches the UID of a dashboard by slug or title.
+func (s *Session) findDashboardUID(ctx context.Context, name string) (string, error) {
+	queries := []string{name}
+	if spaced := strings.ReplaceAll(name, "-", " "); spaced != name {
+		queries = append(queries, spaced)
+	}
+
+	for _, query := range queries {
+		hits, err := s.SearchDashboardsContext(ctx, SearchQuery{Query: query, Type: SearchTypeDashboard})
+		if err != nil {
+			return "", err
+		}
+
+		for _, hit := range hits {
+			slug := path.Base(strings.TrimPrefix(hit.URI, "db/"))
+			if hit.URL != "" && hit.URI == "" {
+				slug = path.Base(hit.URL)
+			}
+			if hit.Title == name || slug == name || slugify(hit.Title) == name {
+				return hit.UID, nil
+			}
+		}
+	}
+	return "", GrafanaError{Code: http.StatusNotFound, Description: "Dashboard not found: " + name}
+}
+
+// slugify converts a dashboard title to its slug like Grafana does.
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
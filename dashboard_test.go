@@ -0,0 +1,130 @@
+package grafanaclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newDashboardServer(deleted *string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"database":"ok","version":"10.2.3"}`))
+	})
+	mux.HandleFunc("/api/search", func(w http.ResponseWriter, r *http.Request) {
+		switch strings.ToLower(r.URL.Query().Get("query")) {
+		case "nmon aix":
+			w.Write([]byte(`[{"id":7,"uid":"nmon-aix","title":"Nmon AIX","uri":"db/nmon-aix","url":"/d/nmon-aix/nmon-aix","type":"dash-db"}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	})
+	mux.HandleFunc("/api/dashboards/uid/nmon-aix", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			*deleted = "nmon-aix"
+			w.Write([]byte(`{"title":"Nmon AIX","message":"Dashboard Nmon AIX deleted"}`))
+			return
+		}
+		w.Write([]byte(`{"meta":{"slug":"nmon-aix","uid":"nmon-aix","url":"/d/nmon-aix/nmon-aix","folderId":3,"folderUid":"nmon","version":4},
+			"dashboard":{"id":7,"uid":"nmon-aix","title":"Nmon AIX","rows":[]}}`))
+	})
+	mux.HandleFunc("/api/dashboards/db", func(w http.ResponseWriter, r *http.Request) {
+		var content DashboardUploader
+		json.NewDecoder(r.Body).Decode(&content)
+		w.Write([]byte(`{"id":8,"uid":"` + content.Dashboard.UID + `","url":"/d/` + content.Dashboard.UID + `/cpu","status":"success","version":1,"slug":"cpu"}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func Test_DashboardResultModel(t *testing.T) {
+	var result DashboardResult
+	err := json.Unmarshal([]byte(`{"meta":{"slug":"old"},"model":{"id":1,"title":"Old"}}`), &result)
+	assert.Nil(t, err, "We are expecting no error and got one decoding a Grafana 2 dashboard")
+	assert.Equal(t, "Old", result.Model.Title)
+	assert.Equal(t, "", result.UID())
+
+	err = json.Unmarshal([]byte(`{"meta":{"slug":"new","uid":"abc"},"dashboard":{"id":2,"uid":"abc","title":"New"}}`), &result)
+	assert.Nil(t, err, "We are expecting no error and got one decoding a dashboard")
+	assert.Equal(t, "New", result.Model.Title)
+	assert.Equal(t, "abc", result.UID())
+}
+
+func Test_GetDashboardByUID(t *testing.T) {
+	var deleted string
+	srv := newDashboardServer(&deleted)
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	dashboard, err := session.GetDashboardByUID("nmon-aix")
+	assert.Nil(t, err, "We are expecting no error and got one getting dashboard by UID")
+	assert.Equal(t, "Nmon AIX", dashboard.Model.Title)
+	assert.Equal(t, 3, dashboard.Meta.FolderID)
+	assert.Equal(t, "nmon", dashboard.Meta.FolderUID)
+	assert.Equal(t, 4, dashboard.Meta.Version)
+
+	_, err = session.GetDashboardByUID("unknown")
+	assert.True(t, errors.Is(err, ErrNotFound), "We are expecting a not found error")
+}
+
+func Test_GetDashboardBySlug(t *testing.T) {
+	var deleted string
+	srv := newDashboardServer(&deleted)
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	dashboard, err := session.GetDashboard("nmon-aix")
+	assert.Nil(t, err, "We are expecting no error and got one getting dashboard by slug")
+	assert.Equal(t, "nmon-aix", dashboard.UID())
+
+	dashboard, err = session.GetDashboard("Nmon AIX")
+	assert.Nil(t, err, "We are expecting no error and got one getting dashboard by title")
+	assert.Equal(t, "nmon-aix", dashboard.UID())
+
+	_, err = session.GetDashboard("unknown")
+	assert.True(t, errors.Is(err, ErrNotFound), "We are expecting a not found error")
+
+	err = session.DeleteDashboard("nmon-aix")
+	assert.Nil(t, err, "We are expecting no error and got one deleting dashboard")
+	assert.Equal(t, "nmon-aix", deleted)
+}
+
+func Test_GetDashboardOldServer(t *testing.T) {
+	var deletedSlug string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/frontend/settings", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"buildInfo":{"version":"4.6.3"}}`))
+	})
+	mux.HandleFunc("/api/dashboards/db/nmon-aix", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deletedSlug = "nmon-aix"
+		}
+		w.Write([]byte(`{"meta":{"slug":"nmon-aix"},"model":{"id":7,"title":"Nmon AIX"}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	dashboard, err := session.GetDashboard("nmon-aix")
+	assert.Nil(t, err, "We are expecting no error and got one getting dashboard from a old server")
+	assert.Equal(t, "Nmon AIX", dashboard.Model.Title)
+
+	err = session.DeleteDashboard("nmon-aix")
+	assert.Nil(t, err, "We are expecting no error and got one deleting dashboard from a old server")
+	assert.Equal(t, "nmon-aix", deletedSlug)
+}
+
+func Test_UploadDashboardResult(t *testing.T) {
+	var deleted string
+	srv := newDashboardServer(&deleted)
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	result, err := session.UploadDashboard(Dashboard{UID: "cpu", Title: "CPU"}, true)
+	assert.Nil(t, err, "We are expecting no error and got one uploading dashboard")
+	assert.Equal(t, DashboardSaveResult{ID: 8, UID: "cpu", URL: "/d/cpu/cpu", Status: "success", Version: 1, Slug: "cpu"}, result)
+}
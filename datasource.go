@@ -0,0 +1,143 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+// DataSource types supported by the helpers of this package
+const (
+	DataSourceInfluxDB      = "influxdb"
+	DataSourcePrometheus    = "prometheus"
+	DataSourceElasticsearch = "elasticsearch"
+	DataSourceGraphite      = "graphite"
+	DataSourceLoki          = "loki"
+)
+
+// InfluxDB query languages usable in the version setting of a InfluxDB DataSource
+const (
+	InfluxQL = "InfluxQL"
+	Flux     = "Flux"
+)
+
+// JSONData contains the type specific settings of a DataSource stored in Grafana jsonData.
+type JSONData map[string]interface{}
+
+// SecureJSONData contains the secrets of a DataSource.
+// Grafana stores them encrypted and never returns them.
+type SecureJSONData map[string]string
+
+// String returns the setting as a string, or an empty string if it's missing or not a string.
+func (data JSONData) String(key string) string {
+	value, _ := data[key].(string)
+	return value
+}
+
+// Bool returns the setting as a bool, or false if it's missing or not a bool.
+func (data JSONData) Bool(key string) bool {
+	value, _ := data[key].(bool)
+	return value
+}
+
+// SetJSONData sets a setting in the DataSource jsonData.
+func (ds *DataSource) SetJSONData(key string, value interface{}) {
+	if ds.JSONData == nil {
+		ds.JSONData = JSONData{}
+	}
+	ds.JSONData[key] = value
+}
+
+// SetSecureJSONData sets a secret in the DataSource secureJsonData.
+func (ds *DataSource) SetSecureJSONData(key string, value string) {
+	if ds.SecureJSONData == nil {
+		ds.SecureJSONData = SecureJSONData{}
+	}
+	ds.SecureJSONData[key] = value
+}
+
+// HasSecret reports if Grafana has a value stored for the secret.
+func (ds DataSource) HasSecret(key string) bool {
+	return ds.SecureJSONFields[key]
+}
+
+// SetBasicAuth enables HTTP basic authentication from Grafana to the DataSource.
+// The password is stored in secureJsonData.
+func (ds *DataSource) SetBasicAuth(user string, password string) {
+	ds.BasicAuth = true
+	ds.BasicAuthUser = user
+	ds.SetSecureJSONData("basicAuthPassword", password)
+}
+
+// newProxyDataSource create a DataSource accessed through the Grafana server.
+func newProxyDataSource(name string, dsType string, url string) DataSource {
+	return DataSource{Name: name, Type: dsType, URL: url, Access: "proxy", JSONData: JSONData{}}
+}
+
+// NewInfluxDBDataSource create a InfluxDB DataSource queried with InfluxQL.
+// user and password are optional, the password is stored in secureJsonData.
+func NewInfluxDBDataSource(name string, url string, database string, user string, password string) DataSource {
+	ds := newProxyDataSource(name, DataSourceInfluxDB, url)
+	ds.Database = database
+	ds.User = user
+	ds.SetJSONData("version", InfluxQL)
+	ds.SetJSONData("dbName", database)
+	ds.SetJSONData("httpMode", "GET")
+	if password != "" {
+		ds.SetSecureJSONData("password", password)
+	}
+	return ds
+}
+
+// NewInfluxDBFluxDataSource create a InfluxDB 2.x DataSource queried with Flux.
+// The token is stored in secureJsonData.
+func NewInfluxDBFluxDataSource(name string, url string, organization string, bucket string, token string) DataSource {
+	ds := newProxyDataSource(name, DataSourceInfluxDB, url)
+	ds.SetJSONData("version", Flux)
+	ds.SetJSONData("organization", organization)
+	ds.SetJSONData("defaultBucket", bucket)
+	ds.SetSecureJSONData("token", token)
+	return ds
+}
+
+// NewPrometheusDataSource create a Prometheus DataSource queried with POST requests.
+func NewPrometheusDataSource(name string, url string) DataSource {
+	ds := newProxyDataSource(name, DataSourcePrometheus, url)
+	ds.SetJSONData("httpMethod", "POST")
+	return ds
+}
+
+// NewElasticsearchDataSource create a Elasticsearch DataSource on the given index pattern.
+// timeField is the name of the document time field and esVersion the Elasticsearch version, like "7.10.0".
+func NewElasticsearchDataSource(name string, url string, index string, timeField string, esVersion string) DataSource {
+	ds := newProxyDataSource(name, DataSourceElasticsearch, url)
+	ds.Database = index
+	ds.SetJSONData("index", index)
+	ds.SetJSONData("timeField", timeField)
+	ds.SetJSONData("esVersion", esVersion)
+	return ds
+}
+
+// NewGraphiteDataSource create a Graphite DataSource.
+// version is the Graphite version, like "1.1".
+func NewGraphiteDataSource(name string, url string, version string) DataSource {
+	ds := newProxyDataSource(name, DataSourceGraphite, url)
+	ds.SetJSONData("graphiteVersion", version)
+	return ds
+}
+
+// NewLokiDataSource create a Loki DataSource returning at most maxLines log lines per query.
+func NewLokiDataSource(name string, url string, maxLines int) DataSource {
+	ds := newProxyDataSource(name, DataSourceLoki, url)
+	if maxLines > 0 {
+		ds.SetJSONData("maxLines", maxLines)
+	}
+	return ds
+}
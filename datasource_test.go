@@ -0,0 +1,60 @@
+package grafanaclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DataSourceJSONRoundTrip(t *testing.T) {
+	raw := `{"id":3,"uid":"P951FEA4DE68E13C5","orgId":1,"name":"prom","type":"prometheus","access":"proxy",
+		"url":"http://prometheus:9090","withCredentials":true,"readOnly":true,
+		"jsonData":{"httpMethod":"POST","timeInterval":"15s","tlsSkipVerify":true},
+		"secureJsonFields":{"basicAuthPassword":true}}`
+
+	var source DataSource
+	err := json.Unmarshal([]byte(raw), &source)
+	assert.Nil(t, err, "We are expecting no error and got one decoding DataSource")
+	assert.Equal(t, "P951FEA4DE68E13C5", source.UID)
+	assert.True(t, source.WithCredentials)
+	assert.True(t, source.ReadOnly)
+	assert.Equal(t, "POST", source.JSONData.String("httpMethod"))
+	assert.True(t, source.JSONData.Bool("tlsSkipVerify"))
+	assert.True(t, source.HasSecret("basicAuthPassword"))
+
+	out, err := json.Marshal(source)
+	assert.Nil(t, err, "We are expecting no error and got one encoding DataSource")
+	var again DataSource
+	json.Unmarshal(out, &again)
+	assert.Equal(t, source, again, "We are expecting the DataSource to survive a round trip")
+}
+
+func Test_DataSourceHelpers(t *testing.T) {
+	flux := NewInfluxDBFluxDataSource("flux", "http://influx:8086", "myorg", "nmon", "s3cr3t")
+	out, _ := json.Marshal(flux)
+	var body map[string]interface{}
+	json.Unmarshal(out, &body)
+
+	assert.Equal(t, "influxdb", body["type"])
+	assert.Equal(t, "proxy", body["access"])
+	assert.Equal(t, map[string]interface{}{"version": "Flux", "organization": "myorg", "defaultBucket": "nmon"}, body["jsonData"])
+	assert.Equal(t, map[string]interface{}{"token": "s3cr3t"}, body["secureJsonData"])
+
+	influx := NewInfluxDBDataSource("nmon", "http://influx:8086", "nmon", "root", "root")
+	assert.Equal(t, InfluxQL, influx.JSONData.String("version"))
+	assert.Equal(t, "nmon", influx.Database)
+	assert.Equal(t, "root", influx.SecureJSONData["password"])
+
+	es := NewElasticsearchDataSource("logs", "http://es:9200", "logs-*", "@timestamp", "7.10.0")
+	assert.Equal(t, "@timestamp", es.JSONData.String("timeField"))
+
+	loki := NewLokiDataSource("loki", "http://loki:3100", 0)
+	_, ok := loki.JSONData["maxLines"]
+	assert.False(t, ok, "We are not expecting maxLines when not set")
+
+	prom := NewPrometheusDataSource("prom", "http://prometheus:9090")
+	prom.SetBasicAuth("admin", "pw")
+	assert.Equal(t, "admin", prom.BasicAuthUser)
+	assert.Equal(t, "pw", prom.SecureJSONData["basicAuthPassword"])
+}
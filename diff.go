@@ -0,0 +1,303 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Operations of a DiffOperation, named like the JSON patch ones.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// A DiffOperation contains a single difference between two JSON documents.
+// It's encoded as a JSON patch operation: Path is a JSON pointer and Value the new value.
+// OldValue keeps the previous value of replaced and removed elements.
+type DiffOperation struct {
+	Op       string      `json:"op"`
+	Path     string      `json:"path"`
+	Value    interface{} `json:"value,omitempty"`
+	OldValue interface{} `json:"-"`
+}
+
+// MarshalJSON encodes the operation as a JSON patch operation.
+// The value of add and replace operations is always written, even when it's null.
+func (op DiffOperation) MarshalJSON() ([]byte, error) {
+	if op.Op == OpRemove {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{op.Op, op.Path})
+	}
+	return json.Marshal(struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}{op.Op, op.Path, op.Value})
+}
+
+// String returns the operation as a line of text.
+func (op DiffOperation) String() string {
+	switch op.Op {
+	case OpAdd:
+		return fmt.Sprintf("+ %s: %s", op.Path, diffValue(op.Value))
+	case OpRemove:
+		return fmt.Sprintf("- %s: %s", op.Path, diffValue(op.OldValue))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", op.Path, diffValue(op.OldValue), diffValue(op.Value))
+}
+
+// A Diff contains the differences between two JSON documents.
+type Diff []DiffOperation
+
+// Empty reports if the documents are identical.
+func (d Diff) Empty() bool {
+	return len(d) == 0
+}
+
+// String returns the differences as text, one operation per line.
+func (d Diff) String() string {
+	var b strings.Builder
+	for _, op := range d {
+		b.WriteString(op.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// JSONPatch encodes the differences as a JSON patch document.
+func (d Diff) JSONPatch() ([]byte, error) {
+	if d == nil {
+		d = Diff{}
+	}
+	return json.Marshal(d)
+}
+
+// diffJSON compares two decoded JSON documents and returns the operations turning old into new.
+// Object keys are compared in sorted order and array elements by index.
+func diffJSON(path string, old interface{}, new interface{}) (diff Diff) {
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := make(map[string]bool)
+		for key := range oldMap {
+			keys[key] = true
+		}
+		for key := range newMap {
+			keys[key] = true
+		}
+		var sorted []string
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		for _, key := range sorted {
+			oldValue, inOld := oldMap[key]
+			newValue, inNew := newMap[key]
+			keyPath := path + "/" + escapePointer(key)
+			switch {
+			case !inOld:
+				diff = append(diff, DiffOperation{Op: OpAdd, Path: keyPath, Value: newValue})
+			case !inNew:
+				diff = append(diff, DiffOperation{Op: OpRemove, Path: keyPath, OldValue: oldValue})
+			default:
+				diff = append(diff, diffJSON(keyPath, oldValue, newValue)...)
+			}
+		}
+		return
+	}
+
+	oldList, oldIsList := old.([]interface{})
+	newList, newIsList := new.([]interface{})
+	if oldIsList && newIsList {
+		for i := 0; i < len(oldList) && i < len(newList); i++ {
+			diff = append(diff, diffJSON(path+"/"+strconv.Itoa(i), oldList[i], newList[i])...)
+		}
+		for i := len(oldList); i < len(newList); i++ {
+			diff = append(diff, DiffOperation{Op: OpAdd, Path: path + "/" + strconv.Itoa(i), Value: newList[i]})
+		}
+		// removed from the end so the indexes of the remaining elements stay valid
+		for i := len(oldList) - 1; i >= len(newList); i-- {
+			diff = append(diff, DiffOperation{Op: OpRemove, Path: path + "/" + strconv.Itoa(i), OldValue: oldList[i]})
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(old, new) {
+		diff = append(diff, DiffOperation{Op: OpReplace, Path: path, Value: new, OldValue: old})
+	}
+	return
+}
+
+// DiffDashboards compares two dashboards, like the current one returned by GetDashboard
+// and the one about to be uploaded. The returned operations turn current into desired.
+//
+// Rows are matched by title, panels of rows and of the grid layout by ID or title and
+// template variables by name, so moving an element is not reported as a change.
+// Elements without a match are matched by position, and the remaining ones are added or removed.
+// The volatile fields are ignored: the dashboard ID and version, the panel IDs, and the UID
+// when desired has none.
+func DiffDashboards(current Dashboard, desired Dashboard) (Diff, error) {
+	var docs [2]map[string]interface{}
+	for i, dashboard := range []Dashboard{current, desired} {
+		data, err := json.Marshal(dashboard)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, &docs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	ignored := []string{"id", "version", "rows", "panels"}
+	if desired.UID == "" {
+		ignored = append(ignored, "uid")
+	}
+	// the templating is added or removed as a whole when a dashboard has none
+	_, oldTemplating := docs[0]["templating"]
+	_, newTemplating := docs[1]["templating"]
+	if oldTemplating && newTemplating {
+		ignored = append(ignored, "templating")
+	}
+	diff := diffJSON("", without(docs[0], ignored...), without(docs[1], ignored...))
+
+	var diffPanels func(string, interface{}, interface{}) Diff
+	diffPanels = func(path string, old interface{}, new interface{}) Diff {
+		// row panels of the grid layout contain their panels when collapsed
+		panelDiff := diffJSON(path, without(old, "id", "panels"), without(new, "id", "panels"))
+		return append(panelDiff, diffList(path+"/panels", field(old, "panels"), field(new, "panels"), []string{"id", "title"}, diffPanels)...)
+	}
+	diffRows := func(path string, old interface{}, new interface{}) Diff {
+		rowDiff := diffJSON(path, without(old, "panels"), without(new, "panels"))
+		return append(rowDiff, diffList(path+"/panels", field(old, "panels"), field(new, "panels"), []string{"id", "title"}, diffPanels)...)
+	}
+	diff = append(diff, diffList("/rows", docs[0]["rows"], docs[1]["rows"], []string{"title"}, diffRows)...)
+	diff = append(diff, diffList("/panels", docs[0]["panels"], docs[1]["panels"], []string{"id", "title"}, diffPanels)...)
+
+	if oldTemplating && newTemplating {
+		templates := [2]interface{}{field(docs[0]["templating"], "list"), field(docs[1]["templating"], "list")}
+		diff = append(diff, diffJSON("/templating", without(docs[0]["templating"], "list"), without(docs[1]["templating"], "list"))...)
+		diff = append(diff, diffList("/templating/list", templates[0], templates[1], []string{"name"}, diffJSON)...)
+	}
+	return diff, nil
+}
+
+// diffList compares two lists whose elements are matched by the first key having the same
+// non empty value, or else by position. The matched elements are compared by diffElement,
+// then the new elements are appended and the remaining ones removed.
+// When there is no old list, the new one is added as a whole.
+func diffList(path string, old interface{}, new interface{}, keys []string, diffElement func(string, interface{}, interface{}) Diff) (diff Diff) {
+	oldList, oldIsList := old.([]interface{})
+	newList, _ := new.([]interface{})
+	if !oldIsList && len(newList) > 0 {
+		return Diff{{Op: OpAdd, Path: path, Value: new}}
+	}
+
+	matches := make([]int, len(newList))
+	used := make([]bool, len(oldList))
+	for i, element := range newList {
+		matches[i] = -1
+		for _, key := range keys {
+			value := field(element, key)
+			if value == nil || value == "" || value == float64(0) {
+				continue
+			}
+			for j := range oldList {
+				if !used[j] && reflect.DeepEqual(field(oldList[j], key), value) {
+					matches[i] = j
+					break
+				}
+			}
+			if matches[i] >= 0 {
+				break
+			}
+		}
+		if matches[i] >= 0 {
+			used[matches[i]] = true
+		}
+	}
+	for i := range newList {
+		if matches[i] < 0 && i < len(oldList) && !used[i] {
+			matches[i] = i
+			used[i] = true
+		}
+	}
+
+	for j := range oldList {
+		for i := range newList {
+			if matches[i] == j {
+				diff = append(diff, diffElement(path+"/"+strconv.Itoa(j), oldList[j], newList[i])...)
+			}
+		}
+	}
+	for i := range newList {
+		if matches[i] < 0 {
+			diff = append(diff, DiffOperation{Op: OpAdd, Path: path + "/-", Value: newList[i]})
+		}
+	}
+	for j := len(oldList) - 1; j >= 0; j-- {
+		if !used[j] {
+			diff = append(diff, DiffOperation{Op: OpRemove, Path: path + "/" + strconv.Itoa(j), OldValue: oldList[j]})
+		}
+	}
+	return
+}
+
+// field returns the value of a key of a decoded JSON object, or nil.
+func field(value interface{}, key string) interface{} {
+	if object, ok := value.(map[string]interface{}); ok {
+		return object[key]
+	}
+	return nil
+}
+
+// without returns a copy of a decoded JSON object without the given keys.
+// Other values are returned as is.
+func without(value interface{}, keys ...string) interface{} {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	copied := make(map[string]interface{}, len(object))
+	for key, v := range object {
+		copied[key] = v
+	}
+	for _, key := range keys {
+		delete(copied, key)
+	}
+	return copied
+}
+
+// escapePointer escapes a key used in a JSON pointer.
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// diffValue returns a value as compact JSON.
+func diffValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
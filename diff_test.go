@@ -0,0 +1,214 @@
+package grafanaclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DiffJSON(t *testing.T) {
+	var old, new interface{}
+	json.Unmarshal([]byte(`{"a/b":1,"list":[1,2,3],"nested":{"x":"y"}}`), &old)
+	json.Unmarshal([]byte(`{"a/b":2,"list":[1],"nested":{"x":"y"},"c~":true}`), &new)
+
+	diff := diffJSON("", old, new)
+	assert.Equal(t, Diff{
+		{Op: OpReplace, Path: "/a~1b", Value: float64(2), OldValue: float64(1)},
+		{Op: OpAdd, Path: "/c~0", Value: true},
+		{Op: OpRemove, Path: "/list/2", OldValue: float64(3)},
+		{Op: OpRemove, Path: "/list/1", OldValue: float64(2)},
+	}, diff)
+	assert.Equal(t, "~ /a~1b: 1 -> 2\n+ /c~0: true\n- /list/2: 3\n- /list/1: 2\n", diff.String())
+
+	patch, err := diff.JSONPatch()
+	assert.Nil(t, err, "We are expecting no error and got one encoding the patch")
+	assert.JSONEq(t, `[
+		{"op":"replace","path":"/a~1b","value":2},
+		{"op":"add","path":"/c~0","value":true},
+		{"op":"remove","path":"/list/2"},
+		{"op":"remove","path":"/list/1"}
+	]`, string(patch))
+
+	patch, err = Diff{{Op: OpReplace, Path: "/refresh", OldValue: "1m"}}.JSONPatch()
+	assert.Nil(t, err, "We are expecting no error and got one encoding the patch")
+	assert.JSONEq(t, `[{"op":"replace","path":"/refresh","value":null}]`, string(patch))
+
+	assert.True(t, diffJSON("", old, old).Empty())
+}
+
+func Test_DiffDashboards(t *testing.T) {
+	cpu := NewPanel()
+	cpu.ID = 1
+	cpu.Title = "CPU"
+	cpu.AddTarget(Target{Measurement: "CPU_ALL", Alias: "$tag_host"})
+	mem := NewPanel()
+	mem.ID = 2
+	mem.Title = "MEM"
+
+	current := Dashboard{ID: 7, UID: "nmon", Title: "Nmon", Version: 4, GTime: NewGTime()}
+	row := NewRow()
+	row.Title = "system"
+	row.AddPanel(cpu)
+	row.AddPanel(mem)
+	current.AddRow(row)
+	current.Templating.List = Templates{{Name: "host", Query: "SHOW TAG VALUES WITH KEY = host"}}
+
+	desired := Dashboard{Title: "Nmon", GTime: NewGTime()}
+	desired.GTime.From = "now-6h"
+	mem.ID = 10
+	cpu.ID = 11
+	cpu.Targets = []Target{{Measurement: "CPU_ALL", Alias: "$tag_name"}}
+	disk := NewPanel()
+	disk.Title = "DISK"
+	row = NewRow()
+	row.Title = "system"
+	row.AddPanel(mem)
+	row.AddPanel(cpu)
+	row.AddPanel(disk)
+	desired.AddRow(row)
+	desired.Templating.List = Templates{{Name: "host", Query: "SHOW TAG VALUES WITH KEY = host"}}
+
+	diff, err := DiffDashboards(current, desired)
+	assert.Nil(t, err, "We are expecting no error and got one comparing dashboards")
+	assert.Len(t, diff, 3, diff.String())
+	assert.Equal(t, DiffOperation{Op: OpReplace, Path: "/time/from", Value: "now-6h", OldValue: "now-24h"}, diff[0])
+	assert.Equal(t, DiffOperation{Op: OpReplace, Path: "/rows/0/panels/0/targets/0/alias", Value: "$tag_name", OldValue: "$tag_host"}, diff[1])
+	assert.Equal(t, OpAdd, diff[2].Op)
+	assert.Equal(t, "/rows/0/panels/-", diff[2].Path)
+
+	desired.Templating.List = nil
+	desired.Rows = nil
+	diff, err = DiffDashboards(current, desired)
+	assert.Nil(t, err, "We are expecting no error and got one comparing dashboards")
+	assert.Equal(t, "/rows/0", diff[1].Path)
+	assert.Equal(t, OpRemove, diff[1].Op)
+	assert.Equal(t, "/templating/list/0", diff[2].Path)
+
+	diff, err = DiffDashboards(current, current)
+	assert.Nil(t, err, "We are expecting no error and got one comparing dashboards")
+	assert.True(t, diff.Empty())
+}
+
+func Test_DiffDashboardsGrid(t *testing.T) {
+	row := NewRowPanel("system")
+	row.ID = 1
+	cpu := NewPanel()
+	cpu.ID = 2
+	cpu.Title = "CPU"
+	cpu.GridPos = &GridPos{H: 8, W: 12}
+	row.AddPanel(cpu)
+	row.Collapsed = true
+
+	current := Dashboard{Title: "Nmon"}
+	current.AddPanel(row)
+
+	cpu.GridPos = &GridPos{H: 8, W: 24}
+	row.Panels = []Panel{cpu}
+	desired := Dashboard{Title: "Nmon"}
+	desired.AddPanel(row)
+
+	diff, err := DiffDashboards(current, desired)
+	assert.Nil(t, err, "We are expecting no error and got one comparing dashboards")
+	assert.Equal(t, Diff{{Op: OpReplace, Path: "/panels/0/panels/0/gridPos/w", Value: float64(24), OldValue: float64(12)}}, diff)
+}
+
+func Test_DiffDashboardsMissingLists(t *testing.T) {
+	var current Dashboard
+	err := json.Unmarshal([]byte(`{"title":"Nmon","time":{"from":"now-24h","to":"now"},"rows":[{"title":"system"}]}`), &current)
+	assert.Nil(t, err, "We are expecting no error and got one decoding the dashboard")
+
+	desired := current
+	cpu := NewPanel()
+	cpu.ID = 1
+	cpu.Title = "CPU"
+	row := NewRow()
+	row.Title = "system"
+	row.AddPanel(cpu)
+	desired.Rows = []Row{row}
+	desired.AddPanel(NewRowPanel("grid"))
+	desired.Templating.List = Templates{{Name: "host", Query: "SHOW TAG VALUES WITH KEY = host"}}
+
+	diff, err := DiffDashboards(current, desired)
+	assert.Nil(t, err, "We are expecting no error and got one comparing dashboards")
+	for _, op := range diff {
+		assert.False(t, strings.HasSuffix(op.Path, "/-"), "We are expecting the missing lists to be added as a whole and got %s", op.String())
+	}
+
+	var doc, want interface{}
+	data, _ := json.Marshal(current)
+	json.Unmarshal(data, &doc)
+	data, _ = json.Marshal(desired)
+	json.Unmarshal(data, &want)
+	patched, err := applyPatch(doc, diff)
+	assert.Nil(t, err, "We are expecting the patch to apply and got %v", err)
+	assert.Equal(t, want, patched)
+}
+
+// applyPatch applies the operations of a diff like a RFC 6902 JSON patch, failing on missing targets.
+func applyPatch(doc interface{}, diff Diff) (interface{}, error) {
+	for _, op := range diff {
+		tokens := strings.Split(op.Path, "/")[1:]
+		for i, token := range tokens {
+			tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		}
+		var err error
+		if doc, err = applyOperation(doc, tokens, op); err != nil {
+			return nil, fmt.Errorf("%s: %v", op.String(), err)
+		}
+	}
+	return doc, nil
+}
+
+func applyOperation(value interface{}, tokens []string, op DiffOperation) (interface{}, error) {
+	if len(tokens) == 0 {
+		return op.Value, nil
+	}
+	last := len(tokens) == 1
+	switch container := value.(type) {
+	case map[string]interface{}:
+		child, ok := container[tokens[0]]
+		if last {
+			if !ok && op.Op != OpAdd {
+				return nil, fmt.Errorf("missing key %s", tokens[0])
+			}
+			if op.Op == OpRemove {
+				delete(container, tokens[0])
+			} else {
+				container[tokens[0]] = op.Value
+			}
+			return container, nil
+		}
+		if !ok {
+			return nil, fmt.Errorf("missing key %s", tokens[0])
+		}
+		child, err := applyOperation(child, tokens[1:], op)
+		container[tokens[0]] = child
+		return container, err
+	case []interface{}:
+		if last && op.Op == OpAdd && tokens[0] == "-" {
+			return append(container, op.Value), nil
+		}
+		index, err := strconv.Atoi(tokens[0])
+		if err != nil || index < 0 || index > len(container) || (index == len(container) && !(last && op.Op == OpAdd)) {
+			return nil, fmt.Errorf("invalid index %s", tokens[0])
+		}
+		if !last {
+			container[index], err = applyOperation(container[index], tokens[1:], op)
+			return container, err
+		}
+		switch op.Op {
+		case OpAdd:
+			container = append(container[:index], append([]interface{}{op.Value}, container[index:]...)...)
+		case OpRemove:
+			container = append(container[:index], container[index+1:]...)
+		default:
+			container[index] = op.Value
+		}
+		return container, nil
+	}
+	return nil, fmt.Errorf("missing container for %s", tokens[0])
+}
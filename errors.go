@@ -0,0 +1,117 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Sentinel errors matched by a GrafanaError with errors.Is.
+// ErrUnsupportedVersion is matched through the wrapped error.
+var (
+	// ErrNotFound is returned when the requested object doesn't exist (HTTP 404).
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when the object already exists or was modified
+	// by someone else, like a dashboard version mismatch (HTTP 409 and 412).
+	ErrConflict = errors.New("conflict")
+	// ErrUnauthorized is returned when the credentials are missing or invalid (HTTP 401).
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the user is not allowed to perform the request (HTTP 403).
+	ErrForbidden = errors.New("forbidden")
+	// ErrValidation is returned when Grafana rejects the request content (HTTP 400 and 422).
+	ErrValidation = errors.New("validation failed")
+	// ErrUnsupportedVersion is returned when a call is not available with the Grafana server version.
+	ErrUnsupportedVersion = errors.New("unsupported Grafana version")
+)
+
+// GrafanaError is a error structure to handle error messages in this library
+// Method and URL identify the failed request, Body contains the raw response body
+// and Err the underlying error if any.
+type GrafanaError struct {
+	Code        int
+	Description string
+	Method      string
+	URL         string
+	Body        []byte
+	Err         error
+}
+
+// A GrafanaMessage contains the json error message received when http request failed
+type GrafanaMessage struct {
+	Message string `json:"message"`
+}
+
+// Error generate a text error message.
+// If Code is zero, we know it's not a http error.
+func (h GrafanaError) Error() string {
+	msg := fmt.Sprintf("ERROR: %s", h.Description)
+	if h.Code != 0 {
+		msg = fmt.Sprintf("HTTP %d: %s", h.Code, h.Description)
+	}
+	if h.Method != "" {
+		msg = fmt.Sprintf("%s (%s %s)", msg, h.Method, h.URL)
+	}
+	if h.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, h.Err.Error())
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (h GrafanaError) Unwrap() error {
+	return h.Err
+}
+
+// Is reports if the HTTP status code matches one of the sentinel errors.
+func (h GrafanaError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return h.Code == http.StatusNotFound
+	case ErrConflict:
+		return h.Code == http.StatusConflict || h.Code == http.StatusPreconditionFailed
+	case ErrUnauthorized:
+		return h.Code == http.StatusUnauthorized
+	case ErrForbidden:
+		return h.Code == http.StatusForbidden
+	case ErrValidation:
+		return h.Code == http.StatusBadRequest || h.Code == http.StatusUnprocessableEntity
+	}
+	return false
+}
+
+// newResponseError builds a GrafanaError from a failed http response.
+// The body is read and closed, its json message is used as description.
+func newResponseError(method string, url string, response *http.Response) error {
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+
+	var gMess GrafanaMessage
+	json.Unmarshal(body, &gMess)
+	if gMess.Message == "" {
+		gMess.Message = http.StatusText(response.StatusCode)
+	}
+
+	return GrafanaError{
+		Code:        response.StatusCode,
+		Description: gMess.Message,
+		Method:      method,
+		URL:         url,
+		Body:        body,
+		Err:         err,
+	}
+}
@@ -0,0 +1,67 @@
+package grafanaclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ErrorSentinels(t *testing.T) {
+	codes := map[int]error{
+		http.StatusNotFound:            ErrNotFound,
+		http.StatusConflict:            ErrConflict,
+		http.StatusPreconditionFailed:  ErrConflict,
+		http.StatusUnauthorized:        ErrUnauthorized,
+		http.StatusForbidden:           ErrForbidden,
+		http.StatusBadRequest:          ErrValidation,
+		http.StatusUnprocessableEntity: ErrValidation,
+	}
+
+	for code, sentinel := range codes {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(code)
+			w.Write([]byte(`{"message":"failure"}`))
+		}))
+
+		session := NewTokenSession("secret", srv.URL)
+		_, err := session.GetDataSourceList()
+		srv.Close()
+
+		assert.True(t, errors.Is(err, sentinel), "We are expecting %v for HTTP %d and got %v", sentinel, code, err)
+
+		var gErr GrafanaError
+		assert.True(t, errors.As(err, &gErr), "We are expecting a GrafanaError")
+		assert.Equal(t, code, gErr.Code)
+		assert.Equal(t, "failure", gErr.Description)
+		assert.Equal(t, "GET", gErr.Method)
+		assert.Equal(t, srv.URL+"/api/datasources", gErr.URL)
+		assert.Equal(t, `{"message":"failure"}`, string(gErr.Body))
+	}
+}
+
+func Test_AcceptedIsSuccess(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	session := NewTokenSession("secret", srv.URL)
+	err := session.DeleteDataSource(DataSource{ID: 1})
+	assert.Nil(t, err, "We are expecting no error and got one with HTTP 202")
+}
+
+func Test_TransportErrorIsWrapped(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	session := NewTokenSession("secret", srv.URL)
+	_, err := session.GetDataSourceList()
+
+	var gErr GrafanaError
+	assert.True(t, errors.As(err, &gErr), "We are expecting a GrafanaError")
+	assert.NotNil(t, gErr.Err, "We are expecting the transport error to be kept")
+	assert.False(t, errors.Is(err, ErrNotFound), "We are not expecting a not found error")
+}
@@ -0,0 +1,404 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// The dashboard structures only model a subset of the Grafana dashboard schema.
+// The fields they don't know, like links or the settings of panel plugins,
+// are decoded in their Extra map and encoded back, so a dashboard is not altered
+// by a GetDashboard and UploadDashboard round trip. The known fields missing from
+// the decoded json are not encoded back while they keep their zero value, and the
+// empty ones dropped by omitempty, like "options": [], are kept in Extra.
+
+// A jsonField is a struct field decoded from json.
+type jsonField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// knownFields caches the json fields of each struct type.
+var knownFields sync.Map
+
+// jsonFields returns the json fields decoded in a struct type.
+func jsonFields(t reflect.Type) []jsonField {
+	if fields, ok := knownFields.Load(t); ok {
+		return fields.([]jsonField)
+	}
+
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		options := strings.Split(f.Tag.Get("json"), ",")
+		name := options[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		field := jsonField{name: name, index: i}
+		for _, option := range options[1:] {
+			field.omitEmpty = field.omitEmpty || option == "omitempty"
+		}
+		fields = append(fields, field)
+	}
+	knownFields.Store(t, fields)
+	return fields
+}
+
+// unmarshalExtra decodes data in the struct pointed by v and returns the unknown fields,
+// with the omitempty fields decoded as a zero value, and the json names of the known fields
+// missing from data.
+func unmarshalExtra(data []byte, v interface{}) (extra map[string]json.RawMessage, absent map[string]bool, err error) {
+	if err = json.Unmarshal(data, v); err != nil {
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return nil, nil, nil
+	}
+	present := make(map[string]bool)
+	for key := range fields {
+		present[strings.ToLower(key)] = true
+	}
+
+	value := reflect.ValueOf(v).Elem()
+	known := make(map[string]bool)
+	for _, field := range jsonFields(value.Type()) {
+		name := strings.ToLower(field.name)
+		if present[name] && field.omitEmpty && isEmptyValue(value.Field(field.index)) {
+			// not encoded back by json.Marshal, so it is kept as a unknown field
+			continue
+		}
+		known[name] = true
+		if !present[name] {
+			if absent == nil {
+				absent = make(map[string]bool)
+			}
+			absent[field.name] = true
+		}
+	}
+	for key, value := range fields {
+		if !known[strings.ToLower(key)] {
+			if extra == nil {
+				extra = make(map[string]json.RawMessage)
+			}
+			extra[key] = value
+		}
+	}
+	return
+}
+
+// isEmptyValue reports if a value is dropped by omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Ptr:
+		return v.IsZero()
+	}
+	return false
+}
+
+// marshalExtra encodes the struct v with the unknown fields, and without the absent
+// fields which still have their zero value.
+// A known field always takes precedence over a unknown one with the same name.
+func marshalExtra(v interface{}, extra map[string]json.RawMessage, absent map[string]bool) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || (len(extra) == 0 && len(absent) == 0) {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	value := reflect.ValueOf(v)
+	for _, field := range jsonFields(value.Type()) {
+		if absent[field.name] && value.Field(field.index).IsZero() {
+			delete(fields, field.name)
+		}
+	}
+	for key, value := range extra {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// splitReference removes a field from a json object when its value is an object,
+// like the datasource references {"type": ..., "uid": ...} of Grafana 8.0 and later
+// which don't fit in the datasource name string. The removed value is returned.
+func splitReference(data []byte, key string) ([]byte, json.RawMessage) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return data, nil
+	}
+	value := fields[key]
+	if !isReference(value) {
+		return data, nil
+	}
+
+	delete(fields, key)
+	rest, err := json.Marshal(fields)
+	if err != nil {
+		return data, nil
+	}
+	return rest, value
+}
+
+// isReference reports if a json value is an object, like a datasource reference.
+func isReference(value json.RawMessage) bool {
+	return len(value) > 0 && value[0] == '{'
+}
+
+// setExtra adds a field to a Extra map, creating it if needed.
+func setExtra(extra map[string]json.RawMessage, key string, value json.RawMessage) map[string]json.RawMessage {
+	if value == nil {
+		return extra
+	}
+	if extra == nil {
+		extra = make(map[string]json.RawMessage)
+	}
+	extra[key] = value
+	return extra
+}
+
+// UnmarshalJSON decodes a Dashboard and keeps its unknown fields in Extra.
+func (db *Dashboard) UnmarshalJSON(data []byte) (err error) {
+	type plain Dashboard
+	db.Extra, db.absent, err = unmarshalExtra(data, (*plain)(db))
+	return
+}
+
+// MarshalJSON encodes a Dashboard with its unknown fields.
+func (db Dashboard) MarshalJSON() ([]byte, error) {
+	type plain Dashboard
+	return marshalExtra(plain(db), db.Extra, db.absent)
+}
+
+// UnmarshalJSON decodes a Template and keeps its unknown fields in Extra.
+// A datasource reference is kept in Extra and Datasource stays empty.
+func (template *Template) UnmarshalJSON(data []byte) (err error) {
+	type plain Template
+	data, ref := splitReference(data, "datasource")
+	template.Extra, template.absent, err = unmarshalExtra(data, (*plain)(template))
+	template.Extra = setExtra(template.Extra, "datasource", ref)
+	return
+}
+
+// MarshalJSON encodes a Template with its unknown fields.
+func (template Template) MarshalJSON() ([]byte, error) {
+	type plain Template
+	return marshalExtra(plain(template), template.Extra, template.absent)
+}
+
+// UnmarshalJSON decodes a Row and keeps its unknown fields in Extra.
+func (row *Row) UnmarshalJSON(data []byte) (err error) {
+	type plain Row
+	row.Extra, row.absent, err = unmarshalExtra(data, (*plain)(row))
+	return
+}
+
+// MarshalJSON encodes a Row with its unknown fields.
+func (row Row) MarshalJSON() ([]byte, error) {
+	type plain Row
+	return marshalExtra(plain(row), row.Extra, row.absent)
+}
+
+// UnmarshalJSON decodes a Panel and keeps its unknown fields in Extra.
+// A datasource reference is kept in Extra and DataSource stays empty.
+func (panel *Panel) UnmarshalJSON(data []byte) (err error) {
+	type plain Panel
+	data, ref := splitReference(data, "datasource")
+	panel.Extra, panel.absent, err = unmarshalExtra(data, (*plain)(panel))
+	panel.Extra = setExtra(panel.Extra, "datasource", ref)
+	return
+}
+
+// MarshalJSON encodes a Panel with its unknown fields.
+func (panel Panel) MarshalJSON() ([]byte, error) {
+	type plain Panel
+	return marshalExtra(plain(panel), panel.Extra, panel.absent)
+}
+
+// UnmarshalJSON decodes a Target and keeps its unknown fields in Extra.
+func (target *Target) UnmarshalJSON(data []byte) (err error) {
+	type plain Target
+	target.Extra, target.absent, err = unmarshalExtra(data, (*plain)(target))
+	return
+}
+
+// MarshalJSON encodes a Target with its unknown fields.
+func (target Target) MarshalJSON() ([]byte, error) {
+	type plain Target
+	return marshalExtra(plain(target), target.Extra, target.absent)
+}
+
+// UnmarshalJSON decodes a GroupBy and keeps its unknown fields in Extra.
+func (groupBy *GroupBy) UnmarshalJSON(data []byte) (err error) {
+	type plain GroupBy
+	groupBy.Extra, groupBy.absent, err = unmarshalExtra(data, (*plain)(groupBy))
+	return
+}
+
+// MarshalJSON encodes a GroupBy with its unknown fields.
+func (groupBy GroupBy) MarshalJSON() ([]byte, error) {
+	type plain GroupBy
+	return marshalExtra(plain(groupBy), groupBy.Extra, groupBy.absent)
+}
+
+// UnmarshalJSON decodes a Tag and keeps its unknown fields, like operator, in Extra.
+func (tag *Tag) UnmarshalJSON(data []byte) (err error) {
+	type plain Tag
+	tag.Extra, tag.absent, err = unmarshalExtra(data, (*plain)(tag))
+	return
+}
+
+// MarshalJSON encodes a Tag with its unknown fields.
+func (tag Tag) MarshalJSON() ([]byte, error) {
+	type plain Tag
+	return marshalExtra(plain(tag), tag.Extra, tag.absent)
+}
+
+// UnmarshalJSON decodes a Legend and keeps its unknown fields in Extra.
+func (legend *Legend) UnmarshalJSON(data []byte) (err error) {
+	type plain Legend
+	legend.Extra, legend.absent, err = unmarshalExtra(data, (*plain)(legend))
+	return
+}
+
+// MarshalJSON encodes a Legend with its unknown fields.
+func (legend Legend) MarshalJSON() ([]byte, error) {
+	type plain Legend
+	return marshalExtra(plain(legend), legend.Extra, legend.absent)
+}
+
+// UnmarshalJSON decodes a Tooltip and keeps its unknown fields in Extra.
+func (tooltip *Tooltip) UnmarshalJSON(data []byte) (err error) {
+	type plain Tooltip
+	tooltip.Extra, tooltip.absent, err = unmarshalExtra(data, (*plain)(tooltip))
+	return
+}
+
+// MarshalJSON encodes a Tooltip with its unknown fields.
+func (tooltip Tooltip) MarshalJSON() ([]byte, error) {
+	type plain Tooltip
+	return marshalExtra(plain(tooltip), tooltip.Extra, tooltip.absent)
+}
+
+// UnmarshalJSON decodes a SeriesOverride and keeps its unknown fields in Extra.
+func (override *SeriesOverride) UnmarshalJSON(data []byte) (err error) {
+	type plain SeriesOverride
+	override.Extra, override.absent, err = unmarshalExtra(data, (*plain)(override))
+	return
+}
+
+// MarshalJSON encodes a SeriesOverride with its unknown fields.
+func (override SeriesOverride) MarshalJSON() ([]byte, error) {
+	type plain SeriesOverride
+	return marshalExtra(plain(override), override.Extra, override.absent)
+}
+
+// UnmarshalJSON decodes a FieldConfig and keeps its unknown fields in Extra.
+func (config *FieldConfig) UnmarshalJSON(data []byte) (err error) {
+	type plain FieldConfig
+	config.Extra, config.absent, err = unmarshalExtra(data, (*plain)(config))
+	return
+}
+
+// MarshalJSON encodes a FieldConfig with its unknown fields.
+func (config FieldConfig) MarshalJSON() ([]byte, error) {
+	type plain FieldConfig
+	return marshalExtra(plain(config), config.Extra, config.absent)
+}
+
+// UnmarshalJSON decodes a FieldDefaults and keeps its unknown fields, like links, in Extra.
+func (defaults *FieldDefaults) UnmarshalJSON(data []byte) (err error) {
+	type plain FieldDefaults
+	defaults.Extra, defaults.absent, err = unmarshalExtra(data, (*plain)(defaults))
+	return
+}
+
+// MarshalJSON encodes a FieldDefaults with its unknown fields.
+func (defaults FieldDefaults) MarshalJSON() ([]byte, error) {
+	type plain FieldDefaults
+	return marshalExtra(plain(defaults), defaults.Extra, defaults.absent)
+}
+
+// UnmarshalJSON decodes a TemplateCurrent and keeps its unknown fields, like selected, in Extra.
+func (current *TemplateCurrent) UnmarshalJSON(data []byte) (err error) {
+	type plain TemplateCurrent
+	current.Extra, current.absent, err = unmarshalExtra(data, (*plain)(current))
+	return
+}
+
+// MarshalJSON encodes a TemplateCurrent with its unknown fields.
+func (current TemplateCurrent) MarshalJSON() ([]byte, error) {
+	type plain TemplateCurrent
+	return marshalExtra(plain(current), current.Extra, current.absent)
+}
+
+// UnmarshalJSON decodes a Thresholds and keeps its unknown fields in Extra.
+func (thresholds *Thresholds) UnmarshalJSON(data []byte) (err error) {
+	type plain Thresholds
+	thresholds.Extra, thresholds.absent, err = unmarshalExtra(data, (*plain)(thresholds))
+	return
+}
+
+// MarshalJSON encodes a Thresholds with its unknown fields.
+func (thresholds Thresholds) MarshalJSON() ([]byte, error) {
+	type plain Thresholds
+	return marshalExtra(plain(thresholds), thresholds.Extra, thresholds.absent)
+}
+
+// UnmarshalJSON decodes a ThresholdStep and keeps its unknown fields, like index, in Extra.
+func (step *ThresholdStep) UnmarshalJSON(data []byte) (err error) {
+	type plain ThresholdStep
+	step.Extra, step.absent, err = unmarshalExtra(data, (*plain)(step))
+	return
+}
+
+// MarshalJSON encodes a ThresholdStep with its unknown fields.
+func (step ThresholdStep) MarshalJSON() ([]byte, error) {
+	type plain ThresholdStep
+	return marshalExtra(plain(step), step.Extra, step.absent)
+}
+
+// UnmarshalJSON decodes a ValueMapping and keeps its unknown fields in Extra.
+func (mapping *ValueMapping) UnmarshalJSON(data []byte) (err error) {
+	type plain ValueMapping
+	mapping.Extra, mapping.absent, err = unmarshalExtra(data, (*plain)(mapping))
+	return
+}
+
+// MarshalJSON encodes a ValueMapping with its unknown fields.
+func (mapping ValueMapping) MarshalJSON() ([]byte, error) {
+	type plain ValueMapping
+	return marshalExtra(plain(mapping), mapping.Extra, mapping.absent)
+}
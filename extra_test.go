@@ -0,0 +1,175 @@
+package grafanaclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const modernDashboard = `{
+	"id": 7,
+	"uid": "nmon",
+	"title": "Nmon",
+	"refresh": false,
+	"links": [{"title": "docs", "url": "https://example.com"}],
+	"rows": [{
+		"title": "system",
+		"repeat": "host",
+		"panels": [{
+			"id": 1,
+			"title": "CPU",
+			"type": "timeseries",
+			"pointradius": 0.5,
+			"gridPos": {"h": 8, "w": 12, "x": 0, "y": 0},
+			"fieldConfig": {"defaults": {"unit": "percent", "thresholds": {"mode": "absolute"}}},
+			"options": {"legend": {"displayMode": "table"}},
+			"transformations": [{"id": "organize"}],
+			"legend": {"show": true, "rightSide": true},
+			"tooltip": {"value_type": "individual", "shared": true},
+			"seriesOverrides": [{"alias": "idle", "color": "#FFFFFF"}],
+			"targets": [{
+				"refId": "A",
+				"measurement": "CPU_ALL",
+				"groupBy": [{"type": "fill", "params": ["null"], "extra": 1}],
+				"tags": [{"key": "host", "operator": "=~", "value": "/^$host$/"}]
+			}]
+		}]
+	}],
+	"templating": {"list": [{"name": "host", "type": "query", "definition": "SHOW TAG VALUES WITH KEY = host", "sort": 1, "refresh": 1,
+		"current": {"selected": true, "text": ["lpar1", "lpar2"], "value": ["lpar1", "lpar2"]}}]}
+}`
+
+func Test_DashboardUnknownFields(t *testing.T) {
+	var dashboard Dashboard
+	err := json.Unmarshal([]byte(modernDashboard), &dashboard)
+	assert.Nil(t, err, "We are expecting no error and got one decoding dashboard")
+	assert.Contains(t, dashboard.Extra, "links")
+	assert.NotContains(t, dashboard.Extra, "title")
+	assert.Equal(t, "percent", dashboard.Rows[0].Panels[0].FieldConfig.Defaults.Unit)
+	assert.Contains(t, dashboard.Rows[0].Panels[0].Targets[0].Extra, "refId")
+	assert.Equal(t, false, dashboard.Refresh)
+	assert.Equal(t, 0.5, dashboard.Rows[0].Panels[0].Pointradius)
+	assert.Equal(t, float64(1), dashboard.Templating.List[0].Refresh)
+	assert.Equal(t, []interface{}{"lpar1", "lpar2"}, dashboard.Templating.List[0].Current.Text)
+
+	data, err := json.Marshal(dashboard)
+	assert.Nil(t, err, "We are expecting no error and got one encoding dashboard")
+	assert.JSONEq(t, modernDashboard, string(data), "We are expecting the round trip to be lossless")
+
+	dashboard.Rows[0].Panels[0].Title = "CPU usage"
+	dashboard.Rows[0].Panels[0].Span = 6
+	data, err = json.Marshal(dashboard)
+	assert.Nil(t, err, "We are expecting no error and got one encoding dashboard")
+
+	var encoded map[string]interface{}
+	json.Unmarshal(data, &encoded)
+	assert.Equal(t, []interface{}{map[string]interface{}{"title": "docs", "url": "https://example.com"}}, encoded["links"])
+
+	row := encoded["rows"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "host", row["repeat"])
+	panel := row["panels"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "CPU usage", panel["title"])
+	assert.Equal(t, float64(6), panel["span"], "We are expecting a field set after decoding to be encoded")
+	assert.NotContains(t, panel, "content", "We are expecting no legacy default field")
+	assert.Equal(t, map[string]interface{}{"h": float64(8), "w": float64(12), "x": float64(0), "y": float64(0)}, panel["gridPos"])
+	assert.Equal(t, "percent", panel["fieldConfig"].(map[string]interface{})["defaults"].(map[string]interface{})["unit"])
+	assert.Contains(t, panel, "options")
+	assert.Contains(t, panel, "transformations")
+	assert.Equal(t, true, panel["legend"].(map[string]interface{})["rightSide"])
+	assert.Equal(t, true, panel["tooltip"].(map[string]interface{})["shared"])
+	assert.Equal(t, "#FFFFFF", panel["seriesOverrides"].([]interface{})[0].(map[string]interface{})["color"])
+
+	target := panel["targets"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "A", target["refId"])
+	assert.Equal(t, "=~", target["tags"].([]interface{})[0].(map[string]interface{})["operator"])
+	assert.Equal(t, float64(1), target["groupBy"].([]interface{})[0].(map[string]interface{})["extra"])
+
+	template := encoded["templating"].(map[string]interface{})["list"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "SHOW TAG VALUES WITH KEY = host", template["definition"])
+	assert.NotContains(t, encoded, "editable", "We are expecting no legacy default field")
+}
+
+// A Grafana 9 dashboard with a Prometheus variable and a graph panel stacking a series in group A.
+const grafana9Dashboard = `{
+	"uid": "node",
+	"title": "Node",
+	"schemaVersion": 37,
+	"panels": [{
+		"id": 2,
+		"title": "Load",
+		"type": "graph",
+		"datasource": {"type": "prometheus", "uid": "P1809F7CD0C75ACF3"},
+		"gridPos": {"h": 8, "w": 24, "x": 0, "y": 0},
+		"seriesOverrides": [{"alias": "load15", "stack": "A", "fill": 0}, {"alias": "load1", "stack": false}],
+		"targets": [{"refId": "A", "expr": "node_load1{job=\"$job\"}"}]
+	}],
+	"templating": {"list": [{
+		"name": "job",
+		"type": "query",
+		"datasource": {"type": "prometheus", "uid": "P1809F7CD0C75ACF3"},
+		"definition": "label_values(up, job)",
+		"query": {"query": "label_values(up, job)", "refId": "PrometheusVariableQueryEditor-VariableQuery"},
+		"refresh": 1,
+		"regex": "",
+		"current": {"selected": false, "text": "node", "value": "node"},
+		"options": [],
+		"hide": 0
+	}]}
+}`
+
+func Test_DashboardGrafana9Fields(t *testing.T) {
+	var dashboard Dashboard
+	err := json.Unmarshal([]byte(grafana9Dashboard), &dashboard)
+	assert.Nil(t, err, "We are expecting no error and got one decoding dashboard")
+
+	template := dashboard.Templating.List[0]
+	assert.Equal(t, "label_values(up, job)", template.Query.(map[string]interface{})["query"], "We are expecting the query object to be decoded")
+	overrides := dashboard.Panels[0].SeriesOverrides
+	assert.Equal(t, "A", overrides[0].Stack, "We are expecting the stack group to be decoded")
+	assert.Equal(t, false, overrides[1].Stack)
+
+	data, err := json.Marshal(dashboard)
+	assert.Nil(t, err, "We are expecting no error and got one encoding dashboard")
+	assert.JSONEq(t, grafana9Dashboard, string(data), "We are expecting the round trip to be lossless")
+}
+
+func Test_DashboardKnownFieldsWin(t *testing.T) {
+	panel := NewPanel()
+	panel.Title = "CPU"
+	panel.Extra = map[string]json.RawMessage{"title": json.RawMessage(`"old"`), "gridPos": json.RawMessage(`{"h":8}`)}
+
+	data, err := json.Marshal(panel)
+	assert.Nil(t, err, "We are expecting no error and got one encoding panel")
+
+	var encoded map[string]interface{}
+	json.Unmarshal(data, &encoded)
+	assert.Equal(t, "CPU", encoded["title"])
+	assert.Equal(t, map[string]interface{}{"h": float64(8)}, encoded["gridPos"])
+}
+
+func Test_DataSourceReference(t *testing.T) {
+	var dashboard Dashboard
+	err := json.Unmarshal([]byte(`{"rows":[{"panels":[{"id":1,"type":"timeseries","datasource":{"type":"influxdb","uid":"nmon"}},
+		{"id":2,"type":"graph","datasource":"nmon"}]}],
+		"templating":{"list":[{"name":"host","datasource":{"type":"influxdb","uid":"nmon"}},{"name":"interval","type":"interval"}]}}`), &dashboard)
+	assert.Nil(t, err, "We are expecting no error and got one decoding datasource references")
+	assert.Equal(t, "", dashboard.Rows[0].Panels[0].DataSource)
+	assert.Equal(t, "nmon", dashboard.Rows[0].Panels[1].DataSource)
+
+	data, _ := json.Marshal(dashboard)
+	var encoded struct {
+		Rows []struct {
+			Panels []map[string]interface{} `json:"panels"`
+		} `json:"rows"`
+		Templating struct {
+			List []map[string]interface{} `json:"list"`
+		} `json:"templating"`
+	}
+	json.Unmarshal(data, &encoded)
+	reference := map[string]interface{}{"type": "influxdb", "uid": "nmon"}
+	assert.Equal(t, reference, encoded.Rows[0].Panels[0]["datasource"])
+	assert.Equal(t, "nmon", encoded.Rows[0].Panels[1]["datasource"])
+	assert.Equal(t, reference, encoded.Templating.List[0]["datasource"])
+	assert.NotContains(t, encoded.Templating.List[1], "datasource", "We are expecting no datasource for a interval variable")
+}
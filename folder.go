@@ -0,0 +1,173 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	neturl "net/url"
+)
+
+// A Folder contains the json structure of a Grafana dashboard folder.
+// ParentUID and Parents are only set with nested folders, available since Grafana 10.0.
+type Folder struct {
+	ID        int      `json:"id,omitempty"`
+	UID       string   `json:"uid,omitempty"`
+	Title     string   `json:"title"`
+	URL       string   `json:"url,omitempty"`
+	ParentUID string   `json:"parentUid,omitempty"`
+	Parents   []Folder `json:"parents,omitempty"`
+	Version   int      `json:"version,omitempty"`
+	HasACL    bool     `json:"hasAcl,omitempty"`
+	CanSave   bool     `json:"canSave,omitempty"`
+	CanEdit   bool     `json:"canEdit,omitempty"`
+	CanAdmin  bool     `json:"canAdmin,omitempty"`
+	Created   string   `json:"created,omitempty"`
+	Updated   string   `json:"updated,omitempty"`
+}
+
+// GetFolders returns the top level folders.
+// It returns a error matching ErrUnsupportedVersion with Grafana servers older than 5.0.
+func (s *Session) GetFolders() ([]Folder, error) {
+	return s.GetFoldersContext(context.Background())
+}
+
+// GetFoldersContext is like GetFolders but uses the provided context for the request.
+func (s *Session) GetFoldersContext(ctx context.Context) (folders []Folder, err error) {
+	if _, err = s.requireVersion(ctx, 5, 0, "GetFolders"); err != nil {
+		return
+	}
+	reqURL := s.url + "/api/folders"
+	err = s.doJSON(ctx, "GET", reqURL, nil, &folders)
+	return
+}
+
+// GetChildFolders returns the folders nested in the parent folder.
+// It returns a error matching ErrUnsupportedVersion with Grafana servers older than 10.0.
+func (s *Session) GetChildFolders(parentUID string) ([]Folder, error) {
+	return s.GetChildFoldersContext(context.Background(), parentUID)
+}
+
+// GetChildFoldersContext is like GetChildFolders but uses the provided context for the request.
+func (s *Session) GetChildFoldersContext(ctx context.Context, parentUID string) (folders []Folder, err error) {
+	if _, err = s.requireVersion(ctx, 10, 0, "GetChildFolders"); err != nil {
+		return
+	}
+	reqURL := s.url + "/api/folders?parentUid=" + neturl.QueryEscape(parentUID)
+	err = s.doJSON(ctx, "GET", reqURL, nil, &folders)
+	return
+}
+
+// GetFolderByUID returns a existing folder.
+// It returns a error matching ErrNotFound if the folder doesn't exist.
+func (s *Session) GetFolderByUID(uid string) (Folder, error) {
+	return s.GetFolderByUIDContext(context.Background(), uid)
+}
+
+// GetFolderByUIDContext is like GetFolderByUID but uses the provided context for the request.
+func (s *Session) GetFolderByUIDContext(ctx context.Context, uid string) (folder Folder, err error) {
+	if _, err = s.requireVersion(ctx, 5, 0, "GetFolderByUID"); err != nil {
+		return
+	}
+	reqURL := s.url + "/api/folders/" + neturl.PathEscape(uid)
+	err = s.doJSON(ctx, "GET", reqURL, nil, &folder)
+	return
+}
+
+// CreateFolder creates a folder with the given title, and UID if set.
+// A folder with a ParentUID is created nested in this folder, which needs Grafana 10.0 or later.
+// It returns the created folder with its ID and UID.
+func (s *Session) CreateFolder(folder Folder) (Folder, error) {
+	return s.CreateFolderContext(context.Background(), folder)
+}
+
+// CreateFolderContext is like CreateFolder but uses the provided context for the request.
+func (s *Session) CreateFolderContext(ctx context.Context, folder Folder) (created Folder, err error) {
+	major, feature := 5, "CreateFolder"
+	if folder.ParentUID != "" {
+		major, feature = 10, "nested folders"
+	}
+	if _, err = s.requireVersion(ctx, major, 0, feature); err != nil {
+		return
+	}
+
+	content := struct {
+		UID       string `json:"uid,omitempty"`
+		Title     string `json:"title"`
+		ParentUID string `json:"parentUid,omitempty"`
+	}{folder.UID, folder.Title, folder.ParentUID}
+
+	reqURL := s.url + "/api/folders"
+	err = s.doJSON(ctx, "POST", reqURL, content, &created)
+	return
+}
+
+// UpdateFolder changes the title of a existing folder, identified by its UID.
+// The folder Version must match the current one, it returns a error matching ErrConflict otherwise.
+// A folder without Version overwrites the current one.
+func (s *Session) UpdateFolder(folder Folder) (Folder, error) {
+	return s.UpdateFolderContext(context.Background(), folder)
+}
+
+// UpdateFolderContext is like UpdateFolder but uses the provided context for the request.
+func (s *Session) UpdateFolderContext(ctx context.Context, folder Folder) (updated Folder, err error) {
+	if _, err = s.requireVersion(ctx, 5, 0, "UpdateFolder"); err != nil {
+		return
+	}
+
+	content := struct {
+		Title     string `json:"title"`
+		Version   int    `json:"version,omitempty"`
+		Overwrite bool   `json:"overwrite"`
+	}{folder.Title, folder.Version, folder.Version == 0}
+
+	reqURL := s.url + "/api/folders/" + neturl.PathEscape(folder.UID)
+	err = s.doJSON(ctx, "PUT", reqURL, content, &updated)
+	return
+}
+
+// MoveFolder moves a folder into the parent folder, or to the top level when parentUID is empty.
+// It returns a error matching ErrUnsupportedVersion with Grafana servers older than 10.0.
+func (s *Session) MoveFolder(uid string, parentUID string) (Folder, error) {
+	return s.MoveFolderContext(context.Background(), uid, parentUID)
+}
+
+// MoveFolderContext is like MoveFolder but uses the provided context for the request.
+func (s *Session) MoveFolderContext(ctx context.Context, uid string, parentUID string) (moved Folder, err error) {
+	if _, err = s.requireVersion(ctx, 10, 0, "nested folders"); err != nil {
+		return
+	}
+
+	content := struct {
+		ParentUID string `json:"parentUid"`
+	}{parentUID}
+
+	reqURL := s.url + "/api/folders/" + neturl.PathEscape(uid) + "/move"
+	err = s.doJSON(ctx, "POST", reqURL, content, &moved)
+	return
+}
+
+// DeleteFolder deletes a folder with the dashboards and nested folders it contains.
+// It returns a error matching ErrNotFound if the folder doesn't exist.
+func (s *Session) DeleteFolder(uid string) (err error) {
+	return s.DeleteFolderContext(context.Background(), uid)
+}
+
+// DeleteFolderContext is like DeleteFolder but uses the provided context for the request.
+func (s *Session) DeleteFolderContext(ctx context.Context, uid string) (err error) {
+	if _, err = s.requireVersion(ctx, 5, 0, "DeleteFolder"); err != nil {
+		return
+	}
+	reqURL := s.url + "/api/folders/" + neturl.PathEscape(uid)
+	return s.doJSON(ctx, "DELETE", reqURL, nil, nil)
+}
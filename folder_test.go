@@ -0,0 +1,108 @@
+package grafanaclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FolderLifecycle(t *testing.T) {
+	var calls []string
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+
+		switch r.Method {
+		case "GET":
+			if r.URL.Path == "/api/folders" {
+				w.Write([]byte(`[{"id":1,"uid":"ops","title":"Ops"}]`))
+				return
+			}
+			w.Write([]byte(`{"id":2,"uid":"unix","title":"Unix","parentUid":"ops","parents":[{"uid":"ops","title":"Ops"}],"version":3}`))
+		case "DELETE":
+			w.Write([]byte(`{"message":"Folder deleted"}`))
+		default:
+			w.Write([]byte(`{"id":2,"uid":"unix","title":"Unix","parentUid":"ops","version":4}`))
+		}
+	}))
+	defer srv.Close()
+	session, _ := NewSessionWithOptions(srv.URL, WithToken("secret"), WithServerVersion("10.2.3"))
+
+	folders, err := session.GetFolders()
+	assert.Nil(t, err, "We are expecting no error and got one listing folders")
+	assert.Equal(t, []Folder{{ID: 1, UID: "ops", Title: "Ops"}}, folders)
+
+	_, err = session.GetChildFolders("ops")
+	assert.Nil(t, err, "We are expecting no error and got one listing child folders")
+
+	folder, err := session.GetFolderByUID("unix")
+	assert.Nil(t, err, "We are expecting no error and got one getting folder")
+	assert.Equal(t, "ops", folder.ParentUID)
+	assert.Equal(t, "Ops", folder.Parents[0].Title)
+
+	folder, err = session.CreateFolder(Folder{UID: "unix", Title: "Unix", ParentUID: "ops"})
+	assert.Nil(t, err, "We are expecting no error and got one creating folder")
+	assert.Equal(t, 2, folder.ID)
+
+	folder.Title = "AIX"
+	_, err = session.UpdateFolder(folder)
+	assert.Nil(t, err, "We are expecting no error and got one updating folder")
+
+	_, err = session.MoveFolder("unix", "")
+	assert.Nil(t, err, "We are expecting no error and got one moving folder")
+
+	err = session.DeleteFolder("unix")
+	assert.Nil(t, err, "We are expecting no error and got one deleting folder")
+
+	assert.Equal(t, []string{
+		"GET /api/folders",
+		"GET /api/folders?parentUid=ops",
+		"GET /api/folders/unix",
+		"POST /api/folders",
+		"PUT /api/folders/unix",
+		"POST /api/folders/unix/move",
+		"DELETE /api/folders/unix",
+	}, calls)
+	assert.Equal(t, map[string]interface{}{"uid": "unix", "title": "Unix", "parentUid": "ops"}, bodies[3])
+	assert.Equal(t, map[string]interface{}{"title": "AIX", "version": float64(4), "overwrite": false}, bodies[4])
+	assert.Equal(t, map[string]interface{}{"parentUid": ""}, bodies[5])
+}
+
+func Test_NestedFoldersUnsupported(t *testing.T) {
+	session, _ := NewSessionWithOptions("http://127.0.0.1:1", WithServerVersion("8.5.2"))
+
+	_, err := session.CreateFolder(Folder{Title: "Unix", ParentUID: "ops"})
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion), "We are expecting a unsupported version error")
+
+	_, err = session.MoveFolder("unix", "ops")
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion), "We are expecting a unsupported version error")
+}
+
+func Test_SaveDashboardInFolder(t *testing.T) {
+	var content map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&content)
+		w.Write([]byte(`{"id":8,"uid":"cpu","status":"success","version":2}`))
+	}))
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	result, err := session.SaveDashboard(DashboardUploader{
+		Dashboard: Dashboard{UID: "cpu", Title: "CPU"},
+		Overwrite: true,
+		FolderUID: "unix",
+		Message:   "add CPU panels",
+	})
+	assert.Nil(t, err, "We are expecting no error and got one saving dashboard")
+	assert.Equal(t, 2, result.Version)
+	assert.Equal(t, "unix", content["folderUid"])
+	assert.Equal(t, "add CPU panels", content["message"])
+	assert.NotContains(t, content, "folderId")
+}
@@ -0,0 +1,162 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GeneratorOptions defines the dashboard built by GenerateDashboard.
+type GeneratorOptions struct {
+	// Title of the generated dashboard.
+	Title string
+	// Measurements keeps only the measurements matching the expression. All measurements are kept when nil.
+	Measurements *regexp.Regexp
+	// Hosts keeps only the hosts matching the expression. All hosts are kept when nil.
+	Hosts *regexp.Regexp
+	// HostGroups displays the hosts of each group in a single panel, named after the group.
+	// The hosts which are not part of a group get a panel each.
+	HostGroups map[string][]string
+}
+
+// GenerateDashboard builds a Dashboard from the schema of a InfluxDB DataSource.
+// The measurements and their host and name tag values are discovered through the Grafana
+// datasource proxy. The dashboard has one row per measurement and one panel per host or
+// host group, with targets built like ConvertTemplate builds them from a Metric.
+// The discovered host and field names are escaped and anchored in the target tags.
+// It returns a error if the schema cannot be discovered.
+func (s *Session) GenerateDashboard(ds DataSource, opts GeneratorOptions) (Dashboard, error) {
+	return s.GenerateDashboardContext(context.Background(), ds, opts)
+}
+
+// GenerateDashboardContext is like GenerateDashboard but uses the provided context for the requests.
+func (s *Session) GenerateDashboardContext(ctx context.Context, ds DataSource, opts GeneratorOptions) (dashboard Dashboard, err error) {
+	dashboard.Title = opts.Title
+	dashboard.Editable = true
+	dashboard.GTime = NewGTime()
+
+	measurements, err := s.ShowMeasurementsContext(ctx, ds)
+	if err != nil {
+		return
+	}
+
+	panelID := 1
+	for _, measurement := range measurements {
+		if opts.Measurements != nil && !opts.Measurements.MatchString(measurement) {
+			continue
+		}
+
+		hosts, err := s.ShowTagValuesContext(ctx, ds, measurement, "host")
+		if err != nil {
+			return dashboard, err
+		}
+		hosts = filterStrings(hosts, opts.Hosts)
+		if len(hosts) == 0 {
+			continue
+		}
+
+		fields, err := s.ShowTagValuesContext(ctx, ds, measurement, "name")
+		if err != nil {
+			return dashboard, err
+		}
+
+		row := NewRow()
+		row.Title = measurement
+		for _, group := range groupHosts(hosts, opts.HostGroups) {
+			panel := NewPanel()
+			panel.ID = panelID
+			panel.Title = measurement + " " + group.name
+			panel.DataSource = ds.Name
+
+			metric := Metric{Measurement: measurement, Hosts: exactPatterns(group.hosts), Fields: exactPatterns(fields)}
+			panel.Metrics = append(panel.Metrics, metric)
+			panel.AddTarget(NewMetricTarget(metric))
+
+			row.AddPanel(panel)
+			panelID++
+		}
+		dashboard.AddRow(row)
+	}
+	return
+}
+
+// A hostGroup contains the hosts displayed in a single panel.
+type hostGroup struct {
+	name  string
+	hosts []string
+}
+
+// groupHosts splits the hosts in the configured groups, sorted by name,
+// followed by a group per remaining host.
+func groupHosts(hosts []string, groups map[string][]string) (result []hostGroup) {
+	present := make(map[string]bool)
+	for _, host := range hosts {
+		present[host] = true
+	}
+
+	var names []string
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	grouped := make(map[string]bool)
+	for _, name := range names {
+		group := hostGroup{name: name}
+		for _, host := range groups[name] {
+			if present[host] && !grouped[host] {
+				grouped[host] = true
+				group.hosts = append(group.hosts, host)
+			}
+		}
+		if len(group.hosts) > 0 {
+			result = append(result, group)
+		}
+	}
+
+	for _, host := range hosts {
+		if !grouped[host] {
+			result = append(result, hostGroup{name: host, hosts: []string{host}})
+		}
+	}
+	return
+}
+
+// exactPatterns returns the regular expressions matching exactly each value,
+// so a host like db.example.com doesn't match other hosts in the target tags.
+// The slashes are escaped too, as the target tags delimit the regular expressions with /.
+func exactPatterns(values []string) (patterns []string) {
+	for _, value := range values {
+		quoted := strings.Replace(regexp.QuoteMeta(value), "/", `\/`, -1)
+		patterns = append(patterns, "^"+quoted+"$")
+	}
+	return
+}
+
+// filterStrings returns the values matching the expression, or all values when it's nil.
+func filterStrings(values []string, filter *regexp.Regexp) []string {
+	if filter == nil {
+		return values
+	}
+	var kept []string
+	for _, value := range values {
+		if filter.MatchString(value) {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
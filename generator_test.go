@@ -0,0 +1,53 @@
+package grafanaclient
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GenerateDashboard(t *testing.T) {
+	srv := newInfluxServer(t)
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	dashboard, err := session.GenerateDashboard(influxDataSource, GeneratorOptions{
+		Title:        "nmon",
+		Measurements: regexp.MustCompile(`^DISK`),
+		HostGroups:   map[string][]string{"prod": {"lpar1", "lpar3"}},
+	})
+	assert.Nil(t, err, "We are expecting no error and got one generating the dashboard")
+	assert.Equal(t, "nmon", dashboard.Title)
+	assert.Len(t, dashboard.Rows, 2)
+
+	diskwrite := dashboard.Rows[1]
+	assert.Equal(t, "DISKWRITE", diskwrite.Title)
+	assert.Len(t, diskwrite.Panels, 2)
+	assert.Equal(t, "DISKWRITE prod", diskwrite.Panels[0].Title)
+	assert.Equal(t, "DISKWRITE lpar2", diskwrite.Panels[1].Title)
+	assert.Equal(t, 3, diskwrite.Panels[1].ID, "We are expecting unique panel IDs")
+
+	expected := NewMetricTarget(Metric{Measurement: "DISKWRITE", Hosts: []string{"^lpar1$"}, Fields: []string{"^hdisk1$", "^hdisk2$", "^hdisk3$"}})
+	assert.Equal(t, []Target{expected}, diskwrite.Panels[0].Targets)
+	assert.Equal(t, "/^lpar1$/", diskwrite.Panels[0].Targets[0].Tags[0].Value)
+
+	dashboard, err = session.GenerateDashboard(influxDataSource, GeneratorOptions{Hosts: regexp.MustCompile(`lpar2`)})
+	assert.Nil(t, err, "We are expecting no error and got one generating the dashboard")
+	assert.Len(t, dashboard.Rows, 1, "We are expecting only the measurements with matching hosts")
+}
+
+func Test_ExactPatterns(t *testing.T) {
+	patterns := exactPatterns([]string{"db.example.com", "lpar(1)"})
+	assert.Equal(t, []string{`^db\.example\.com$`, `^lpar\(1\)$`}, patterns)
+	host := regexp.MustCompile(patterns[0])
+	assert.True(t, host.MatchString("db.example.com"))
+	assert.False(t, host.MatchString("dbxexample.com"), "We are expecting the dots to be escaped")
+	assert.False(t, host.MatchString("db.example.com.backup"), "We are expecting the pattern to be anchored")
+
+	patterns = exactPatterns([]string{"/home", "/var"})
+	assert.Equal(t, []string{`^\/home$`, `^\/var$`}, patterns, "We are expecting the slashes to be escaped")
+	assert.Equal(t, `/^\/home$|^\/var$/`, "/"+strings.Join(patterns, "|")+"/")
+	assert.True(t, regexp.MustCompile(patterns[0]).MatchString("/home"))
+}
@@ -0,0 +1,172 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"sync"
+)
+
+// Status of a DataSourceHealth
+const (
+	HealthOK    = "OK"
+	HealthError = "ERROR"
+)
+
+// healthCheckWorkers is the number of datasources checked at the same time by CheckDataSources.
+const healthCheckWorkers = 8
+
+// proxyProbes contains the path requested through the datasource proxy to test a datasource
+// when the health endpoint is not available.
+var proxyProbes = map[string]string{
+	DataSourceInfluxDB:      "/ping",
+	DataSourcePrometheus:    "/api/v1/query?query=1",
+	DataSourceElasticsearch: "/",
+	DataSourceGraphite:      "/metrics/find?query=*",
+	DataSourceLoki:          "/loki/api/v1/labels",
+}
+
+// A DataSourceHealth contains the result of a DataSource health check.
+// Err is set when the check itself failed, like a Grafana server unreachable.
+type DataSourceHealth struct {
+	ID      int             `json:"id"`
+	UID     string          `json:"uid"`
+	Name    string          `json:"name"`
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Details json.RawMessage `json:"details,omitempty"`
+	Err     error           `json:"-"`
+}
+
+// OK reports if Grafana can reach the DataSource backend.
+func (h DataSourceHealth) OK() bool {
+	return h.Status == HealthOK
+}
+
+// A DataSourceHealthReport contains the health of several datasources.
+type DataSourceHealthReport []DataSourceHealth
+
+// Broken returns the datasources which failed their health check.
+func (report DataSourceHealthReport) Broken() (broken DataSourceHealthReport) {
+	for _, h := range report {
+		if !h.OK() {
+			broken = append(broken, h)
+		}
+	}
+	return
+}
+
+// CheckDataSourceHealth checks that Grafana can reach the DataSource backend.
+// It uses the /api/datasources/uid/:uid/health endpoint. When it's not available,
+// like on older Grafana versions, a request is sent through the datasource proxy instead.
+// A failed check is not an error: it returns a DataSourceHealth with the ERROR status.
+// It returns a error if the check cannot be performed.
+func (s *Session) CheckDataSourceHealth(ds DataSource) (DataSourceHealth, error) {
+	return s.CheckDataSourceHealthContext(context.Background(), ds)
+}
+
+// CheckDataSourceHealthContext is like CheckDataSourceHealth but uses the provided context for the requests.
+func (s *Session) CheckDataSourceHealthContext(ctx context.Context, ds DataSource) (health DataSourceHealth, err error) {
+	health = DataSourceHealth{ID: ds.ID, UID: ds.UID, Name: ds.Name}
+
+	if ds.UID != "" {
+		reqURL := s.url + "/api/datasources/uid/" + neturl.PathEscape(ds.UID) + "/health"
+		err = s.doJSON(ctx, "GET", reqURL, nil, &health)
+
+		var gErr GrafanaError
+		switch {
+		case err == nil:
+			return
+		case errors.As(err, &gErr) && json.Unmarshal(gErr.Body, &health) == nil && health.Status != "":
+			// Grafana answers a failed check with a error status and the check result as body
+			return health, nil
+		case !errors.Is(err, ErrNotFound):
+			return
+		}
+	}
+
+	return s.checkDataSourceProxy(ctx, ds)
+}
+
+// checkDataSourceProxy tests the DataSource with a request through the Grafana datasource proxy.
+func (s *Session) checkDataSourceProxy(ctx context.Context, ds DataSource) (health DataSourceHealth, err error) {
+	health = DataSourceHealth{ID: ds.ID, UID: ds.UID, Name: ds.Name}
+
+	probe, ok := proxyProbes[ds.Type]
+	if !ok {
+		probe = "/"
+	}
+	reqURL := fmt.Sprintf("%s/api/datasources/proxy/%d%s", s.url, ds.ID, probe)
+
+	_, err = s.httpRequest(ctx, "GET", reqURL, nil)
+	var gErr GrafanaError
+	switch {
+	case err == nil:
+		health.Status = HealthOK
+		health.Message = "Data source proxy request succeeded"
+	case errors.As(err, &gErr) && gErr.Code >= http.StatusBadRequest && gErr.Code != http.StatusUnauthorized && gErr.Code != http.StatusForbidden:
+		health.Status = HealthError
+		health.Message = gErr.Description
+		err = nil
+	}
+	return
+}
+
+// CheckDataSources checks the health of every DataSource returned by GetDataSourceList.
+// The datasources are checked concurrently. A datasource whose check cannot be performed
+// is reported with the ERROR status and the failure in Err.
+// It returns a error if the DataSource list cannot be retrieved.
+func (s *Session) CheckDataSources() (DataSourceHealthReport, error) {
+	return s.CheckDataSourcesContext(context.Background())
+}
+
+// CheckDataSourcesContext is like CheckDataSources but uses the provided context for the requests.
+func (s *Session) CheckDataSourcesContext(ctx context.Context) (report DataSourceHealthReport, err error) {
+	list, err := s.GetDataSourceListContext(ctx)
+	if err != nil {
+		return
+	}
+
+	report = make(DataSourceHealthReport, len(list))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < healthCheckWorkers && w < len(list); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				health, checkErr := s.CheckDataSourceHealthContext(ctx, list[i])
+				if checkErr != nil {
+					health.Status = HealthError
+					health.Message = checkErr.Error()
+					health.Err = checkErr
+				}
+				report[i] = health
+			}
+		}()
+	}
+
+	for i := range list {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return
+}
@@ -0,0 +1,73 @@
+package grafanaclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newHealthServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/datasources", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id":1,"uid":"good","name":"prom","type":"prometheus"},
+			{"id":2,"uid":"bad","name":"loki","type":"loki"},
+			{"id":3,"name":"old-influx","type":"influxdb"},
+			{"id":4,"name":"down-influx","type":"influxdb"}
+		]`))
+	})
+	mux.HandleFunc("/api/datasources/uid/good/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"OK","message":"Successfully queried the Prometheus API."}`))
+	})
+	mux.HandleFunc("/api/datasources/uid/bad/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"ERROR","message":"Unable to connect with Loki."}`))
+	})
+	mux.HandleFunc("/api/datasources/proxy/3/ping", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/api/datasources/proxy/4/ping", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`{"message":"Bad Gateway"}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func Test_CheckDataSourceHealth(t *testing.T) {
+	srv := newHealthServer()
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	health, err := session.CheckDataSourceHealth(DataSource{ID: 1, UID: "good", Name: "prom"})
+	assert.Nil(t, err, "We are expecting no error and got one checking health")
+	assert.True(t, health.OK())
+	assert.Equal(t, "Successfully queried the Prometheus API.", health.Message)
+
+	health, err = session.CheckDataSourceHealth(DataSource{ID: 2, UID: "bad", Name: "loki"})
+	assert.Nil(t, err, "We are expecting a failed check not to be an error")
+	assert.False(t, health.OK())
+	assert.Equal(t, "Unable to connect with Loki.", health.Message)
+
+	health, err = session.CheckDataSourceHealth(DataSource{ID: 3, Name: "old-influx", Type: "influxdb"})
+	assert.Nil(t, err, "We are expecting no error and got one checking health through the proxy")
+	assert.True(t, health.OK())
+}
+
+func Test_CheckDataSources(t *testing.T) {
+	srv := newHealthServer()
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	report, err := session.CheckDataSources()
+	assert.Nil(t, err, "We are expecting no error and got one checking datasources")
+	assert.Len(t, report, 4)
+	assert.Equal(t, "prom", report[0].Name, "We are expecting the report in list order")
+
+	var broken []string
+	for _, h := range report.Broken() {
+		broken = append(broken, h.Name)
+	}
+	assert.Equal(t, []string{"loki", "down-influx"}, broken)
+}
@@ -0,0 +1,212 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+)
+
+// A DashboardVersion contains a version of a Dashboard kept by Grafana.
+// Data is only set by GetDashboardVersion.
+type DashboardVersion struct {
+	ID            int       `json:"id"`
+	DashboardID   int       `json:"dashboardId"`
+	DashboardUID  string    `json:"dashboardUid,omitempty"`
+	ParentVersion int       `json:"parentVersion"`
+	RestoredFrom  int       `json:"restoredFrom"`
+	Version       int       `json:"version"`
+	Created       string    `json:"created"`
+	CreatedBy     string    `json:"createdBy"`
+	Message       string    `json:"message"`
+	Data          Dashboard `json:"data"`
+}
+
+// versionsURL returns the URL of the versions of a dashboard.
+// The UID is used since Grafana 9.0, the ID before.
+func (s *Session) versionsURL(ctx context.Context, dashboard Dashboard) (string, error) {
+	v, err := s.requireVersion(ctx, 4, 0, "dashboard versions")
+	if err != nil {
+		return "", err
+	}
+	if v.AtLeast(9, 0) && dashboard.UID != "" {
+		return s.url + "/api/dashboards/uid/" + neturl.PathEscape(dashboard.UID), nil
+	}
+	if dashboard.ID == 0 {
+		return "", GrafanaError{Description: fmt.Sprintf("dashboard %s has no ID", dashboard.Title)}
+	}
+	return fmt.Sprintf("%s/api/dashboards/id/%d", s.url, dashboard.ID), nil
+}
+
+// GetDashboardVersions returns the versions of a dashboard, newest first.
+// The dashboard is identified by its UID, or its ID with Grafana servers older than 9.0.
+// It returns a error matching ErrUnsupportedVersion with Grafana servers older than 4.0.
+func (s *Session) GetDashboardVersions(dashboard Dashboard) ([]DashboardVersion, error) {
+	return s.GetDashboardVersionsContext(context.Background(), dashboard)
+}
+
+// GetDashboardVersionsContext is like GetDashboardVersions but uses the provided context for the requests.
+func (s *Session) GetDashboardVersionsContext(ctx context.Context, dashboard Dashboard) (versions []DashboardVersion, err error) {
+	baseURL, err := s.versionsURL(ctx, dashboard)
+	if err != nil {
+		return
+	}
+
+	token := ""
+	for {
+		reqURL := baseURL + "/versions"
+		if token != "" {
+			reqURL += "?continueToken=" + neturl.QueryEscape(token)
+		}
+
+		var page json.RawMessage
+		if err = s.doJSON(ctx, "GET", reqURL, nil, &page); err != nil {
+			return
+		}
+
+		// newer Grafana versions return a page of versions with a continue token
+		var list struct {
+			ContinueToken string             `json:"continueToken"`
+			Versions      []DashboardVersion `json:"versions"`
+		}
+		if len(page) > 0 && page[0] == '[' {
+			err = json.Unmarshal(page, &list.Versions)
+		} else {
+			err = json.Unmarshal(page, &list)
+		}
+		if err != nil {
+			return versions, GrafanaError{Description: "cannot decode dashboard versions", Method: "GET", URL: reqURL, Err: err}
+		}
+
+		versions = append(versions, list.Versions...)
+		if list.ContinueToken == "" || len(list.Versions) == 0 {
+			return
+		}
+		token = list.ContinueToken
+	}
+}
+
+// GetDashboardVersion returns a version of a dashboard, with the dashboard content in Data.
+// It returns a error matching ErrNotFound if the version doesn't exist.
+func (s *Session) GetDashboardVersion(dashboard Dashboard, version int) (DashboardVersion, error) {
+	return s.GetDashboardVersionContext(context.Background(), dashboard, version)
+}
+
+// GetDashboardVersionContext is like GetDashboardVersion but uses the provided context for the requests.
+func (s *Session) GetDashboardVersionContext(ctx context.Context, dashboard Dashboard, version int) (dv DashboardVersion, err error) {
+	dv, _, err = s.getDashboardVersion(ctx, dashboard, version)
+	return
+}
+
+// getDashboardVersion returns a version of a dashboard and its raw content.
+func (s *Session) getDashboardVersion(ctx context.Context, dashboard Dashboard, version int) (dv DashboardVersion, data json.RawMessage, err error) {
+	baseURL, err := s.versionsURL(ctx, dashboard)
+	if err != nil {
+		return
+	}
+
+	var content struct {
+		DashboardVersion
+		Data json.RawMessage `json:"data"`
+	}
+	reqURL := baseURL + "/versions/" + strconv.Itoa(version)
+	if err = s.doJSON(ctx, "GET", reqURL, nil, &content); err != nil {
+		return
+	}
+
+	dv = content.DashboardVersion
+	if len(content.Data) > 0 {
+		if err = json.Unmarshal(content.Data, &dv.Data); err != nil {
+			err = GrafanaError{Description: "cannot decode dashboard version", Method: "GET", URL: reqURL, Err: err}
+		}
+	}
+	return dv, content.Data, err
+}
+
+// RestoreDashboardVersion restores a previous version of a dashboard.
+// Grafana saves the restored content as a new version.
+// It returns the save result of the new version.
+func (s *Session) RestoreDashboardVersion(dashboard Dashboard, version int) (DashboardSaveResult, error) {
+	return s.RestoreDashboardVersionContext(context.Background(), dashboard, version)
+}
+
+// RestoreDashboardVersionContext is like RestoreDashboardVersion but uses the provided context for the requests.
+func (s *Session) RestoreDashboardVersionContext(ctx context.Context, dashboard Dashboard, version int) (result DashboardSaveResult, err error) {
+	baseURL, err := s.versionsURL(ctx, dashboard)
+	if err != nil {
+		return
+	}
+
+	content := struct {
+		Version int `json:"version"`
+	}{version}
+	err = s.doJSON(ctx, "POST", baseURL+"/restore", content, &result)
+	return
+}
+
+// RollbackDashboard restores the version preceding the current version of a dashboard,
+// like after a bad dashboard upload.
+// It returns a error matching ErrNotFound if the dashboard has no previous version.
+func (s *Session) RollbackDashboard(dashboard Dashboard) (DashboardSaveResult, error) {
+	return s.RollbackDashboardContext(context.Background(), dashboard)
+}
+
+// RollbackDashboardContext is like RollbackDashboard but uses the provided context for the requests.
+func (s *Session) RollbackDashboardContext(ctx context.Context, dashboard Dashboard) (result DashboardSaveResult, err error) {
+	versions, err := s.GetDashboardVersionsContext(ctx, dashboard)
+	if err != nil {
+		return
+	}
+
+	current, previous := 0, 0
+	for _, dv := range versions {
+		if dv.Version > current {
+			current = dv.Version
+		}
+	}
+	for _, dv := range versions {
+		if dv.Version < current && dv.Version > previous {
+			previous = dv.Version
+		}
+	}
+	if previous == 0 {
+		return result, GrafanaError{Code: http.StatusNotFound, Description: fmt.Sprintf("dashboard %s has no previous version", dashboard.Title)}
+	}
+	return s.RestoreDashboardVersionContext(ctx, dashboard, previous)
+}
+
+// DiffDashboardVersions compares two versions of a dashboard.
+// The returned operations turn the base version content into the new version content.
+func (s *Session) DiffDashboardVersions(dashboard Dashboard, base int, new int) (Diff, error) {
+	return s.DiffDashboardVersionsContext(context.Background(), dashboard, base, new)
+}
+
+// DiffDashboardVersionsContext is like DiffDashboardVersions but uses the provided context for the requests.
+func (s *Session) DiffDashboardVersionsContext(ctx context.Context, dashboard Dashboard, base int, new int) (Diff, error) {
+	var docs [2]interface{}
+	for i, version := range []int{base, new} {
+		_, data, err := s.getDashboardVersion(ctx, dashboard, version)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, &docs[i]); err != nil {
+			return nil, GrafanaError{Description: "cannot decode dashboard version", Err: err}
+		}
+	}
+	return diffJSON("", docs[0], docs[1]), nil
+}
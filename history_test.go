@@ -0,0 +1,119 @@
+package grafanaclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newHistoryServer(restored *int) *httptest.Server {
+	versions := `[
+		{"id":13,"dashboardId":7,"dashboardUid":"nmon","parentVersion":2,"version":3,"created":"2026-10-01T10:00:00Z","createdBy":"deploy","message":"bad push"},
+		{"id":12,"dashboardId":7,"dashboardUid":"nmon","parentVersion":1,"version":2,"created":"2026-09-01T10:00:00Z","createdBy":"admin"},
+		{"id":11,"dashboardId":7,"dashboardUid":"nmon","version":1,"created":"2026-08-01T10:00:00Z","createdBy":"admin"}
+	]`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/dashboards/uid/nmon/versions", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(versions))
+	})
+	mux.HandleFunc("/api/dashboards/id/7/versions", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(versions))
+	})
+	mux.HandleFunc("/api/dashboards/uid/nmon/versions/2", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":12,"version":2,"data":{"id":7,"uid":"nmon","title":"Nmon","refresh":"1m","tags":["aix"]}}`))
+	})
+	mux.HandleFunc("/api/dashboards/uid/nmon/versions/3", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":13,"version":3,"data":{"id":7,"uid":"nmon","title":"Nmon AIX","tags":["aix","power"],"style":"dark"}}`))
+	})
+	mux.HandleFunc("/api/dashboards/uid/nmon/restore", func(w http.ResponseWriter, r *http.Request) {
+		var content struct {
+			Version int `json:"version"`
+		}
+		json.NewDecoder(r.Body).Decode(&content)
+		*restored = content.Version
+		w.Write([]byte(`{"id":7,"uid":"nmon","status":"success","version":4}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func Test_GetDashboardVersions(t *testing.T) {
+	var restored int
+	srv := newHistoryServer(&restored)
+	defer srv.Close()
+
+	for _, version := range []string{"10.2.3", "8.5.2"} {
+		session, _ := NewSessionWithOptions(srv.URL, WithServerVersion(version))
+		versions, err := session.GetDashboardVersions(Dashboard{ID: 7, UID: "nmon"})
+		assert.Nil(t, err, "We are expecting no error and got one listing versions with "+version)
+		assert.Len(t, versions, 3)
+		assert.Equal(t, "bad push", versions[0].Message)
+	}
+
+	session, _ := NewSessionWithOptions(srv.URL, WithServerVersion("3.1.1"))
+	_, err := session.GetDashboardVersions(Dashboard{ID: 7})
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion), "We are expecting a unsupported version error")
+}
+
+func Test_GetDashboardVersionsPages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("continueToken") == "" {
+			w.Write([]byte(`{"continueToken":"next","versions":[{"version":3},{"version":2}]}`))
+			return
+		}
+		w.Write([]byte(`{"versions":[{"version":1}]}`))
+	}))
+	defer srv.Close()
+	session, _ := NewSessionWithOptions(srv.URL, WithServerVersion("12.0.0"))
+
+	versions, err := session.GetDashboardVersions(Dashboard{UID: "nmon"})
+	assert.Nil(t, err, "We are expecting no error and got one listing versions")
+	assert.Len(t, versions, 3)
+	assert.Equal(t, 1, versions[2].Version)
+}
+
+func Test_GetDashboardVersion(t *testing.T) {
+	var restored int
+	srv := newHistoryServer(&restored)
+	defer srv.Close()
+	session, _ := NewSessionWithOptions(srv.URL, WithServerVersion("10.2.3"))
+
+	dv, err := session.GetDashboardVersion(Dashboard{UID: "nmon"}, 2)
+	assert.Nil(t, err, "We are expecting no error and got one getting version")
+	assert.Equal(t, 2, dv.Version)
+	assert.Equal(t, "Nmon", dv.Data.Title)
+
+	_, err = session.GetDashboardVersion(Dashboard{UID: "nmon"}, 9)
+	assert.True(t, errors.Is(err, ErrNotFound), "We are expecting a not found error")
+}
+
+func Test_RollbackDashboard(t *testing.T) {
+	var restored int
+	srv := newHistoryServer(&restored)
+	defer srv.Close()
+	session, _ := NewSessionWithOptions(srv.URL, WithServerVersion("10.2.3"))
+
+	result, err := session.RollbackDashboard(Dashboard{UID: "nmon"})
+	assert.Nil(t, err, "We are expecting no error and got one rolling back")
+	assert.Equal(t, 2, restored, "We are expecting the version before the current one to be restored")
+	assert.Equal(t, 4, result.Version)
+}
+
+func Test_DiffDashboardVersions(t *testing.T) {
+	var restored int
+	srv := newHistoryServer(&restored)
+	defer srv.Close()
+	session, _ := NewSessionWithOptions(srv.URL, WithServerVersion("10.2.3"))
+
+	diff, err := session.DiffDashboardVersions(Dashboard{UID: "nmon"}, 2, 3)
+	assert.Nil(t, err, "We are expecting no error and got one comparing versions")
+	assert.Equal(t, Diff{
+		{Op: OpRemove, Path: "/refresh", OldValue: "1m"},
+		{Op: OpAdd, Path: "/style", Value: "dark"},
+		{Op: OpAdd, Path: "/tags/1", Value: "power"},
+		{Op: OpReplace, Path: "/title", Value: "Nmon AIX", OldValue: "Nmon"},
+	}, diff)
+}
@@ -0,0 +1,181 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	"fmt"
+	neturl "net/url"
+	"sort"
+	"strings"
+)
+
+// An InfluxResult contains the result of one InfluxQL statement.
+type InfluxResult struct {
+	StatementID int            `json:"statement_id"`
+	Series      []InfluxSeries `json:"series"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// An InfluxSeries contains the rows returned by InfluxDB for one series.
+type InfluxSeries struct {
+	Name    string            `json:"name"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Columns []string          `json:"columns"`
+	Values  [][]interface{}   `json:"values"`
+}
+
+// Column returns the values of a column as strings.
+// It returns nil if the series has no such column.
+func (series InfluxSeries) Column(name string) (values []string) {
+	index := -1
+	for i, column := range series.Columns {
+		if column == name {
+			index = i
+		}
+	}
+	if index < 0 {
+		return
+	}
+
+	for _, row := range series.Values {
+		if index < len(row) && row[index] != nil {
+			values = append(values, fmt.Sprint(row[index]))
+		}
+	}
+	return
+}
+
+// QueryInfluxDB sends a InfluxQL query to a InfluxDB DataSource through the Grafana datasource proxy.
+// The query runs against the DataSource database. Several statements can be separated by semicolons,
+// one InfluxResult is returned per statement.
+// It returns a error if the query fails or if InfluxDB reports an error for a statement.
+func (s *Session) QueryInfluxDB(ds DataSource, query string) ([]InfluxResult, error) {
+	return s.QueryInfluxDBContext(context.Background(), ds, query)
+}
+
+// QueryInfluxDBContext is like QueryInfluxDB but uses the provided context for the request.
+func (s *Session) QueryInfluxDBContext(ctx context.Context, ds DataSource, query string) ([]InfluxResult, error) {
+	if ds.Type != DataSourceInfluxDB || ds.JSONData.String("version") == Flux {
+		return nil, GrafanaError{Description: fmt.Sprintf("datasource %s is not a InfluxQL datasource", ds.Name)}
+	}
+
+	database := ds.Database
+	if database == "" {
+		database = ds.JSONData.String("dbName")
+	}
+
+	params := neturl.Values{}
+	params.Set("db", database)
+	params.Set("q", query)
+	params.Set("epoch", "ms")
+	reqURL := fmt.Sprintf("%s/api/datasources/proxy/%d/query?%s", s.url, ds.ID, params.Encode())
+
+	var response struct {
+		Results []InfluxResult `json:"results"`
+		Error   string         `json:"error"`
+	}
+	if err := s.doJSON(ctx, "GET", reqURL, nil, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return response.Results, GrafanaError{Description: "InfluxDB error: " + response.Error, Method: "GET", URL: reqURL}
+	}
+	for _, result := range response.Results {
+		if result.Error != "" {
+			return response.Results, GrafanaError{Description: "InfluxDB error: " + result.Error, Method: "GET", URL: reqURL}
+		}
+	}
+	return response.Results, nil
+}
+
+// ShowMeasurements returns the measurements of a InfluxDB DataSource.
+func (s *Session) ShowMeasurements(ds DataSource) ([]string, error) {
+	return s.ShowMeasurementsContext(context.Background(), ds)
+}
+
+// ShowMeasurementsContext is like ShowMeasurements but uses the provided context for the request.
+func (s *Session) ShowMeasurementsContext(ctx context.Context, ds DataSource) ([]string, error) {
+	return s.influxColumn(ctx, ds, "SHOW MEASUREMENTS", "name")
+}
+
+// ShowTagKeys returns the tag keys of a measurement of a InfluxDB DataSource.
+// With an empty measurement, the tag keys of every measurement are returned.
+func (s *Session) ShowTagKeys(ds DataSource, measurement string) ([]string, error) {
+	return s.ShowTagKeysContext(context.Background(), ds, measurement)
+}
+
+// ShowTagKeysContext is like ShowTagKeys but uses the provided context for the request.
+func (s *Session) ShowTagKeysContext(ctx context.Context, ds DataSource, measurement string) ([]string, error) {
+	return s.influxColumn(ctx, ds, "SHOW TAG KEYS"+influxFrom(measurement), "tagKey")
+}
+
+// ShowTagValues returns the values of a tag of a measurement of a InfluxDB DataSource,
+// like SHOW TAG VALUES WITH KEY = "host".
+// With an empty measurement, the values from every measurement are returned.
+func (s *Session) ShowTagValues(ds DataSource, measurement string, key string) ([]string, error) {
+	return s.ShowTagValuesContext(context.Background(), ds, measurement, key)
+}
+
+// ShowTagValuesContext is like ShowTagValues but uses the provided context for the request.
+func (s *Session) ShowTagValuesContext(ctx context.Context, ds DataSource, measurement string, key string) ([]string, error) {
+	query := "SHOW TAG VALUES" + influxFrom(measurement) + " WITH KEY = " + quoteInfluxIdent(key)
+	return s.influxColumn(ctx, ds, query, "value")
+}
+
+// ShowFieldKeys returns the field keys of a measurement of a InfluxDB DataSource.
+func (s *Session) ShowFieldKeys(ds DataSource, measurement string) ([]string, error) {
+	return s.ShowFieldKeysContext(context.Background(), ds, measurement)
+}
+
+// ShowFieldKeysContext is like ShowFieldKeys but uses the provided context for the request.
+func (s *Session) ShowFieldKeysContext(ctx context.Context, ds DataSource, measurement string) ([]string, error) {
+	return s.influxColumn(ctx, ds, "SHOW FIELD KEYS"+influxFrom(measurement), "fieldKey")
+}
+
+// influxColumn runs a query and returns the sorted distinct values of a column from every series.
+func (s *Session) influxColumn(ctx context.Context, ds DataSource, query string, column string) ([]string, error) {
+	results, err := s.QueryInfluxDBContext(ctx, ds, query)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	values := []string{}
+	for _, result := range results {
+		for _, series := range result.Series {
+			for _, value := range series.Column(column) {
+				if !seen[value] {
+					seen[value] = true
+					values = append(values, value)
+				}
+			}
+		}
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+// influxFrom returns the FROM clause of a measurement, or nothing if it's empty.
+func influxFrom(measurement string) string {
+	if measurement == "" {
+		return ""
+	}
+	return " FROM " + quoteInfluxIdent(measurement)
+}
+
+// quoteInfluxIdent quotes a InfluxQL identifier.
+func quoteInfluxIdent(name string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(name, `\`, `\\`), `"`, `\"`) + `"`
+}
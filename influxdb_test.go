@@ -0,0 +1,62 @@
+package grafanaclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newInfluxServer fakes the Grafana datasource proxy of a InfluxDB datasource with ID 1
+// containing nmon measurements.
+func newInfluxServer(t *testing.T) *httptest.Server {
+	answers := map[string]string{
+		`SHOW MEASUREMENTS`: `{"results":[{"statement_id":0,"series":[{"name":"measurements","columns":["name"],"values":[["DISKWRITE"],["CPU_ALL"],["DISKREAD"]]}]}]}`,
+		`SHOW TAG VALUES FROM "DISKWRITE" WITH KEY = "host"`: `{"results":[{"statement_id":0,"series":[{"name":"DISKWRITE","columns":["key","value"],"values":[["host","lpar2"],["host","lpar1"]]}]}]}`,
+		`SHOW TAG VALUES FROM "DISKWRITE" WITH KEY = "name"`: `{"results":[{"statement_id":0,"series":[{"name":"DISKWRITE","columns":["key","value"],"values":[["name","hdisk1"],["name","hdisk2"],["name","hdisk3"]]}]}]}`,
+		`SHOW TAG VALUES FROM "DISKREAD" WITH KEY = "host"`:  `{"results":[{"statement_id":0,"series":[{"name":"DISKREAD","columns":["key","value"],"values":[["host","lpar1"]]}]}]}`,
+		`SHOW TAG VALUES FROM "DISKREAD" WITH KEY = "name"`:  `{"results":[{"statement_id":0,"series":[{"name":"DISKREAD","columns":["key","value"],"values":[["name","hdisk1"]]}]}]}`,
+		`SHOW TAG VALUES FROM "CPU_ALL" WITH KEY = "host"`:   `{"results":[{"statement_id":0,"series":[{"name":"CPU_ALL","columns":["key","value"],"values":[["host","lpar1"]]}]}]}`,
+		`SHOW TAG VALUES FROM "CPU_ALL" WITH KEY = "name"`:   `{"results":[{"statement_id":0}]}`,
+		`SHOW FIELD KEYS`: `{"results":[{"statement_id":0,"error":"database not found: nmon"}]}`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/datasources/proxy/1/query", r.URL.Path)
+		assert.Equal(t, "nmon", r.URL.Query().Get("db"))
+		answer, ok := answers[r.URL.Query().Get("q")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"unexpected query"}`))
+			return
+		}
+		w.Write([]byte(answer))
+	}))
+}
+
+var influxDataSource = DataSource{ID: 1, Name: "nmon", Type: DataSourceInfluxDB, Database: "nmon"}
+
+func Test_QueryInfluxDB(t *testing.T) {
+	srv := newInfluxServer(t)
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	results, err := session.QueryInfluxDB(influxDataSource, "SHOW MEASUREMENTS")
+	assert.Nil(t, err, "We are expecting no error and got one querying InfluxDB")
+	assert.Equal(t, []string{"DISKWRITE", "CPU_ALL", "DISKREAD"}, results[0].Series[0].Column("name"))
+
+	measurements, err := session.ShowMeasurements(influxDataSource)
+	assert.Nil(t, err, "We are expecting no error and got one listing measurements")
+	assert.Equal(t, []string{"CPU_ALL", "DISKREAD", "DISKWRITE"}, measurements)
+
+	hosts, err := session.ShowTagValues(influxDataSource, "DISKWRITE", "host")
+	assert.Nil(t, err, "We are expecting no error and got one listing tag values")
+	assert.Equal(t, []string{"lpar1", "lpar2"}, hosts)
+
+	_, err = session.ShowFieldKeys(influxDataSource, "")
+	assert.NotNil(t, err, "We are expecting the InfluxDB statement error")
+
+	_, err = session.QueryInfluxDB(NewInfluxDBFluxDataSource("flux", "http://influx", "org", "bucket", "token"), "SHOW MEASUREMENTS")
+	assert.NotNil(t, err, "We are expecting an error for a Flux datasource")
+}
@@ -0,0 +1,168 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Grid layout settings, like the Grafana dashboard migration uses them.
+const (
+	// GridColumns is the width of the dashboard grid.
+	GridColumns = 24
+	// GridSchemaVersion is the first dashboard schema version using the grid layout.
+	GridSchemaVersion = 16
+
+	gridCellHeight   = 30
+	gridCellMargin   = 8
+	minPanelHeight   = 3 * gridCellHeight
+	defaultRowHeight = 250
+	defaultPanelSpan = 4
+)
+
+// A GridPos contains the position and size of a Panel in the dashboard grid.
+// X and W are in columns of the 24 columns grid, Y and H in rows of 30 pixels.
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// NewRowPanel create a row panel, used to group the panels which follow it in the grid layout.
+func NewRowPanel(title string) Panel {
+	return Panel{
+		Type:    PanelRow,
+		Title:   title,
+		GridPos: &GridPos{H: 1, W: GridColumns},
+	}
+}
+
+// AddPanel add a panel to a row panel, used when the row is collapsed.
+func (panel *Panel) AddPanel(child Panel) {
+	panel.Panels = append(panel.Panels, child)
+}
+
+// GridLayout returns a copy of the dashboard with the rows converted to the grid layout
+// of schema version 16, like Grafana converts them when loading a old dashboard.
+// Each row becomes a row panel followed by its panels, unless the dashboard has a single
+// untitled row. The panels of a collapsed row are moved into the row panel.
+// A span is converted to twice as many grid columns and the row height, like 200px,
+// to grid rows. The panels without ID get one.
+func (db Dashboard) GridLayout() Dashboard {
+	converted := db
+	converted.Rows = nil
+	converted.Panels = append([]Panel(nil), db.Panels...)
+	if converted.SchemaVersion < GridSchemaVersion {
+		converted.SchemaVersion = GridSchemaVersion
+	}
+
+	nextID := 1
+	y := 0
+	for _, panel := range db.Panels {
+		nextID = maxInt(nextID, maxPanelID(panel)+1)
+		if panel.GridPos != nil {
+			y = maxInt(y, panel.GridPos.Y+panel.GridPos.H)
+		}
+	}
+	for _, row := range db.Rows {
+		for _, panel := range row.Panels {
+			nextID = maxInt(nextID, panel.ID+1)
+		}
+	}
+
+	showRows := len(db.Rows) > 1 || (len(db.Rows) == 1 && db.Rows[0].Title != "")
+	for _, row := range db.Rows {
+		height := gridHeight(row.Height)
+
+		var rowPanel Panel
+		if showRows {
+			rowPanel = NewRowPanel(row.Title)
+			rowPanel.ID = nextID
+			rowPanel.Collapsed = row.Collapse
+			rowPanel.GridPos.Y = y
+			rowPanel.Extra = row.Extra
+			nextID++
+			y++
+		}
+
+		x, lineHeight := 0, 0
+		var panels []Panel
+		for _, panel := range row.Panels {
+			if panel.ID == 0 {
+				panel.ID = nextID
+				nextID++
+			}
+
+			span := panel.Span
+			if span <= 0 {
+				span = defaultPanelSpan
+			}
+			width := span * GridColumns / 12
+			if x+width > GridColumns {
+				x = 0
+				y += lineHeight
+			}
+			panel.GridPos = &GridPos{H: height, W: width, X: x, Y: y}
+			panels = append(panels, panel)
+
+			x += width
+			lineHeight = height
+		}
+		y += lineHeight
+
+		if showRows && row.Collapse {
+			rowPanel.Panels = panels
+			// the panels of a collapsed row don't take space in the grid
+			y = rowPanel.GridPos.Y + 1
+			converted.Panels = append(converted.Panels, rowPanel)
+			continue
+		}
+		if showRows {
+			converted.Panels = append(converted.Panels, rowPanel)
+		}
+		converted.Panels = append(converted.Panels, panels...)
+	}
+	return converted
+}
+
+// gridHeight converts a row height, like 200px, to grid rows.
+func gridHeight(height string) int {
+	pixels, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(height, "px")))
+	if err != nil || pixels <= 0 {
+		pixels = defaultRowHeight
+	}
+	if pixels < minPanelHeight {
+		pixels = minPanelHeight
+	}
+	return (pixels + gridCellHeight + gridCellMargin - 1) / (gridCellHeight + gridCellMargin)
+}
+
+// maxPanelID returns the highest ID of a panel and of the panels it contains.
+func maxPanelID(panel Panel) int {
+	id := panel.ID
+	for _, child := range panel.Panels {
+		id = maxInt(id, maxPanelID(child))
+	}
+	return id
+}
+
+// maxInt returns the larger of two integers.
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
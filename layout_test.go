@@ -0,0 +1,96 @@
+package grafanaclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GridHeight(t *testing.T) {
+	assert.Equal(t, 6, gridHeight("200px"))
+	assert.Equal(t, 7, gridHeight("250px"))
+	assert.Equal(t, 7, gridHeight(""))
+	assert.Equal(t, 3, gridHeight("50px"))
+	assert.Equal(t, 8, gridHeight("300"))
+}
+
+func Test_GridLayout(t *testing.T) {
+	var dashboard Dashboard
+	dashboard.SchemaVersion = 6
+	system := NewRow()
+	system.Title = "system"
+	for _, span := range []int{6, 6, 4} {
+		panel := NewPanel()
+		panel.Span = span
+		system.AddPanel(panel)
+	}
+	dashboard.AddRow(system)
+
+	disk := NewRow()
+	disk.Title = "disk"
+	disk.Height = "300px"
+	disk.Collapse = true
+	panel := NewPanel()
+	panel.ID = 9
+	panel.Span = 12
+	disk.AddPanel(panel)
+	dashboard.AddRow(disk)
+
+	grid := dashboard.GridLayout()
+	assert.Nil(t, grid.Rows)
+	assert.Equal(t, GridSchemaVersion, grid.SchemaVersion)
+	assert.Len(t, grid.Panels, 5)
+
+	assert.Equal(t, "row", grid.Panels[0].Type)
+	assert.Equal(t, "system", grid.Panels[0].Title)
+	assert.Equal(t, GridPos{H: 1, W: 24, X: 0, Y: 0}, *grid.Panels[0].GridPos)
+	assert.Equal(t, GridPos{H: 6, W: 12, X: 0, Y: 1}, *grid.Panels[1].GridPos)
+	assert.Equal(t, GridPos{H: 6, W: 12, X: 12, Y: 1}, *grid.Panels[2].GridPos)
+	assert.Equal(t, GridPos{H: 6, W: 8, X: 0, Y: 7}, *grid.Panels[3].GridPos)
+
+	collapsed := grid.Panels[4]
+	assert.True(t, collapsed.Collapsed)
+	assert.Equal(t, GridPos{H: 1, W: 24, X: 0, Y: 13}, *collapsed.GridPos)
+	assert.Len(t, collapsed.Panels, 1)
+	assert.Equal(t, 9, collapsed.Panels[0].ID)
+	assert.Equal(t, GridPos{H: 8, W: 24, X: 0, Y: 14}, *collapsed.Panels[0].GridPos)
+
+	ids := make(map[int]bool)
+	for _, panel := range grid.Panels {
+		assert.NotZero(t, panel.ID)
+		assert.False(t, ids[panel.ID], "We are expecting unique panel IDs")
+		ids[panel.ID] = true
+	}
+	assert.Len(t, dashboard.Rows, 2, "We are expecting the original dashboard to be unchanged")
+}
+
+func Test_GridLayoutSingleRow(t *testing.T) {
+	var dashboard Dashboard
+	row := NewRow()
+	row.AddPanel(NewPanel())
+	dashboard.AddRow(row)
+
+	grid := dashboard.GridLayout()
+	assert.Len(t, grid.Panels, 1, "We are expecting no row panel for a single untitled row")
+	assert.Equal(t, "graph", grid.Panels[0].Type)
+}
+
+func Test_GridLayoutTemplate(t *testing.T) {
+	dashboard, err := ConvertTemplate("example.toml")
+	assert.Nil(t, err, "We are expecting no error and got one when Converting template")
+
+	grid := dashboard.GridLayout()
+	panels := 0
+	for _, row := range dashboard.Rows {
+		panels += len(row.Panels)
+	}
+	assert.Len(t, grid.Panels, panels+len(dashboard.Rows))
+
+	data, err := json.Marshal(grid)
+	assert.Nil(t, err, "We are expecting no error and got one encoding dashboard")
+	var encoded map[string]interface{}
+	json.Unmarshal(data, &encoded)
+	assert.NotContains(t, encoded, "rows")
+	assert.Contains(t, encoded["panels"].([]interface{})[1], "gridPos")
+}
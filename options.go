@@ -0,0 +1,296 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	neturl "net/url"
+	"time"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// An Option configures a Session created by NewSessionWithOptions.
+type Option func(*sessionConfig) error
+
+// sessionConfig collects the settings applied by the options before the Session is built.
+type sessionConfig struct {
+	timeout      time.Duration
+	insecure     bool
+	rootCAs      *x509.CertPool
+	certificates []tls.Certificate
+	proxy        func(*http.Request) (*neturl.URL, error)
+	client       *http.Client
+	transport    http.RoundTripper
+	user         string
+	password     string
+	token        string
+	basicAuth    bool
+	retry        *RetryPolicy
+	limiter      *rateLimiter
+	orgID        int
+	version      *Version
+}
+
+// NewSessionWithOptions creates a new http connection configured by the given options.
+// The URL url specifies the host and request URI.
+// Without options, it behaves like NewSession without credentials: a 5 seconds timeout,
+// a cookie jar and server certificates verified against the system roots.
+//
+// It returns a Session struct pointer and a error if an option is invalid.
+func NewSessionWithOptions(url string, opts ...Option) (*Session, error) {
+	config := sessionConfig{timeout: defaultTimeout}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := config.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		client:    client,
+		User:      config.user,
+		Password:  config.password,
+		Token:     config.token,
+		url:       url,
+		basicAuth: config.basicAuth,
+		retry:     config.retry,
+		limiter:   config.limiter,
+		orgID:     config.orgID,
+		version:   &versionCache{version: config.version},
+	}, nil
+}
+
+// httpClient builds the http client described by the configuration.
+func (c *sessionConfig) httpClient() (*http.Client, error) {
+	customTLS := c.insecure || c.rootCAs != nil || len(c.certificates) > 0 || c.proxy != nil
+	if customTLS && (c.client != nil || c.transport != nil) {
+		return nil, GrafanaError{Description: "TLS and proxy options cannot be combined with a custom http client or transport"}
+	}
+
+	var client http.Client
+	if c.client != nil {
+		client = *c.client
+	} else {
+		client.Timeout = c.timeout
+		client.Transport = c.transport
+		if client.Transport == nil {
+			client.Transport = c.httpTransport()
+		}
+	}
+
+	if client.Jar == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		client.Jar = jar
+	}
+	return &client, nil
+}
+
+// httpTransport builds a transport from the default one with the TLS and proxy settings applied.
+func (c *sessionConfig) httpTransport() *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: c.insecure,
+		RootCAs:            c.rootCAs,
+		Certificates:       c.certificates,
+	}
+	if c.proxy != nil {
+		tr.Proxy = c.proxy
+	}
+	return tr
+}
+
+// WithCredentials sets the user and password used by DoLogon.
+func WithCredentials(user string, password string) Option {
+	return func(c *sessionConfig) error {
+		c.user = user
+		c.password = password
+		return nil
+	}
+}
+
+// WithBasicAuth sends the user and password with HTTP basic authentication on every request.
+func WithBasicAuth(user string, password string) Option {
+	return func(c *sessionConfig) error {
+		c.user = user
+		c.password = password
+		c.basicAuth = true
+		return nil
+	}
+}
+
+// WithToken sends a Grafana API key or service account token as a bearer token on every request.
+func WithToken(token string) Option {
+	return func(c *sessionConfig) error {
+		c.token = token
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of every http request. The default is 5 seconds.
+// It is ignored when a custom http client is provided.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *sessionConfig) error {
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithCACert adds PEM encoded CA certificates used to verify the Grafana server.
+// The system roots are not used anymore once a CA is provided.
+func WithCACert(pem []byte) Option {
+	return func(c *sessionConfig) error {
+		if c.rootCAs == nil {
+			c.rootCAs = x509.NewCertPool()
+		}
+		if !c.rootCAs.AppendCertsFromPEM(pem) {
+			return GrafanaError{Description: "no valid CA certificate found"}
+		}
+		return nil
+	}
+}
+
+// WithCAFile is like WithCACert but reads the PEM encoded CA bundle from a file.
+func WithCAFile(file string) Option {
+	return func(c *sessionConfig) error {
+		pem, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		return WithCACert(pem)(c)
+	}
+}
+
+// WithClientCertificate adds a client certificate presented to the server for mutual TLS.
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(c *sessionConfig) error {
+		c.certificates = append(c.certificates, cert)
+		return nil
+	}
+}
+
+// WithClientCertificateFile is like WithClientCertificate but loads the PEM encoded
+// certificate and private key from files.
+func WithClientCertificateFile(certFile string, keyFile string) Option {
+	return func(c *sessionConfig) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		return WithClientCertificate(cert)(c)
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the server certificate.
+// It should only be used for testing.
+func WithInsecureSkipVerify() Option {
+	return func(c *sessionConfig) error {
+		c.insecure = true
+		return nil
+	}
+}
+
+// WithProxy sends every request through the proxy at the given URL.
+// Without this option, the proxy is taken from the environment.
+func WithProxy(proxyURL string) Option {
+	return func(c *sessionConfig) error {
+		u, err := neturl.Parse(proxyURL)
+		if err != nil {
+			return err
+		}
+		c.proxy = http.ProxyURL(u)
+		return nil
+	}
+}
+
+// WithProxyFunc selects the proxy of each request with a function like http.ProxyFromEnvironment.
+func WithProxyFunc(proxy func(*http.Request) (*neturl.URL, error)) Option {
+	return func(c *sessionConfig) error {
+		c.proxy = proxy
+		return nil
+	}
+}
+
+// WithHTTPClient uses the given http client for every request.
+// A cookie jar is added to a copy of the client if it has none.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *sessionConfig) error {
+		c.client = client
+		return nil
+	}
+}
+
+// WithOrgID scopes every request of the session to the given organization.
+func WithOrgID(orgID int) Option {
+	return func(c *sessionConfig) error {
+		c.orgID = orgID
+		return nil
+	}
+}
+
+// WithServerVersion sets the Grafana server version instead of detecting it,
+// like when the server hides its version.
+func WithServerVersion(version string) Option {
+	return func(c *sessionConfig) error {
+		v, err := ParseVersion(version)
+		if err != nil {
+			return err
+		}
+		c.version = &v
+		return nil
+	}
+}
+
+// WithRetryPolicy retries the failed requests according to the given policy.
+// Without this option, requests are never retried.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *sessionConfig) error {
+		if policy.MaxAttempts < 1 {
+			return GrafanaError{Description: "retry policy needs at least one attempt"}
+		}
+		c.retry = &policy
+		return nil
+	}
+}
+
+// WithRateLimit limits the session to the given number of requests per second.
+// Up to burst requests can be sent at once before the limit applies.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(c *sessionConfig) error {
+		if requestsPerSecond <= 0 || burst < 1 {
+			return GrafanaError{Description: "rate limit needs a positive rate and burst"}
+		}
+		c.limiter = newRateLimiter(requestsPerSecond, burst)
+		return nil
+	}
+}
+
+// WithTransport uses the given round tripper to perform the http requests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *sessionConfig) error {
+		c.transport = transport
+		return nil
+	}
+}
@@ -0,0 +1,63 @@
+package grafanaclient
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTLSTestServer() *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	}))
+}
+
+func Test_NewSessionVerifiesCertificates(t *testing.T) {
+	srv := newTLSTestServer()
+	defer srv.Close()
+
+	session := NewSession(user, pass, srv.URL)
+	_, err := session.GetDataSourceList()
+	assert.NotNil(t, err, "We are expecting an error with an unknown certificate authority")
+}
+
+func Test_WithInsecureSkipVerify(t *testing.T) {
+	srv := newTLSTestServer()
+	defer srv.Close()
+
+	session, err := NewSessionWithOptions(srv.URL, WithInsecureSkipVerify())
+	assert.Nil(t, err, "We are expecting no error and got one creating the session")
+	_, err = session.GetDataSourceList()
+	assert.Nil(t, err, "We are expecting no error and got one getting DataSource")
+}
+
+func Test_WithCACert(t *testing.T) {
+	srv := newTLSTestServer()
+	defer srv.Close()
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	session, err := NewSessionWithOptions(srv.URL, WithCACert(ca), WithTimeout(time.Second))
+	assert.Nil(t, err, "We are expecting no error and got one creating the session")
+	_, err = session.GetDataSourceList()
+	assert.Nil(t, err, "We are expecting no error and got one getting DataSource")
+
+	_, err = NewSessionWithOptions(srv.URL, WithCACert([]byte("garbage")))
+	assert.NotNil(t, err, "We are expecting an error with an invalid CA certificate")
+}
+
+func Test_WithHTTPClient(t *testing.T) {
+	srv := newTLSTestServer()
+	defer srv.Close()
+
+	session, err := NewSessionWithOptions(srv.URL, WithHTTPClient(srv.Client()), WithToken("secret"))
+	assert.Nil(t, err, "We are expecting no error and got one creating the session")
+	_, err = session.GetDataSourceList()
+	assert.Nil(t, err, "We are expecting no error and got one getting DataSource")
+
+	_, err = NewSessionWithOptions(srv.URL, WithHTTPClient(srv.Client()), WithInsecureSkipVerify())
+	assert.NotNil(t, err, "We are expecting an error combining a custom client and TLS options")
+}
@@ -0,0 +1,227 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	"fmt"
+	neturl "net/url"
+)
+
+// An Org contains the json structure of a Grafana organization
+type Org struct {
+	ID      int        `json:"id"`
+	Name    string     `json:"name"`
+	Address OrgAddress `json:"address"`
+}
+
+// An OrgAddress contains the postal address of a Grafana organization
+type OrgAddress struct {
+	Address1 string `json:"address1"`
+	Address2 string `json:"address2"`
+	City     string `json:"city"`
+	ZipCode  string `json:"zipCode"`
+	State    string `json:"state"`
+	Country  string `json:"country"`
+}
+
+// An OrgUser contains the json structure of a user member of a Grafana organization
+type OrgUser struct {
+	OrgID      int    `json:"orgId"`
+	UserID     int    `json:"userId"`
+	Email      string `json:"email"`
+	Name       string `json:"name"`
+	Login      string `json:"login"`
+	Role       string `json:"role"`
+	AvatarURL  string `json:"avatarUrl"`
+	LastSeenAt string `json:"lastSeenAt"`
+}
+
+// Organization roles usable with AddOrgUser and UpdateOrgUser
+const (
+	RoleViewer = "Viewer"
+	RoleEditor = "Editor"
+	RoleAdmin  = "Admin"
+)
+
+// ForOrg returns a copy of the session scoped to the given organization.
+// Every request of the returned session sends the X-Grafana-Org-Id header,
+// so dashboards and datasources calls apply to that organization.
+// The copy shares the http client, credentials and cookies of the session.
+func (s *Session) ForOrg(orgID int) *Session {
+	scoped := *s
+	scoped.orgID = orgID
+	return &scoped
+}
+
+// OrgID returns the organization the session is scoped to, or zero if it uses the user current organization.
+func (s *Session) OrgID() int {
+	return s.orgID
+}
+
+// SwitchOrg changes the current organization of the signed in user.
+// It returns a error if the user is not a member of the organization.
+func (s *Session) SwitchOrg(orgID int) (err error) {
+	return s.SwitchOrgContext(context.Background(), orgID)
+}
+
+// SwitchOrgContext is like SwitchOrg but uses the provided context for the request.
+func (s *Session) SwitchOrgContext(ctx context.Context, orgID int) (err error) {
+	reqURL := fmt.Sprintf("%s/api/user/using/%d", s.url, orgID)
+	return s.doJSON(ctx, "POST", reqURL, nil, nil)
+}
+
+// GetCurrentOrg get the current organization of the session.
+func (s *Session) GetCurrentOrg() (org Org, err error) {
+	return s.GetCurrentOrgContext(context.Background())
+}
+
+// GetCurrentOrgContext is like GetCurrentOrg but uses the provided context for the request.
+func (s *Session) GetCurrentOrgContext(ctx context.Context) (org Org, err error) {
+	reqURL := s.url + "/api/org"
+	err = s.doJSON(ctx, "GET", reqURL, nil, &org)
+	return
+}
+
+// GetOrgs return the list of all organizations.
+// It needs Grafana server admin permissions.
+func (s *Session) GetOrgs() (orgs []Org, err error) {
+	return s.GetOrgsContext(context.Background())
+}
+
+// GetOrgsContext is like GetOrgs but uses the provided context for the request.
+func (s *Session) GetOrgsContext(ctx context.Context) (orgs []Org, err error) {
+	reqURL := s.url + "/api/orgs"
+	err = s.doJSON(ctx, "GET", reqURL, nil, &orgs)
+	return
+}
+
+// GetOrg get a existing organization by ID.
+// It returns a error matching ErrNotFound if the organization doesn't exist.
+func (s *Session) GetOrg(orgID int) (org Org, err error) {
+	return s.GetOrgContext(context.Background(), orgID)
+}
+
+// GetOrgContext is like GetOrg but uses the provided context for the request.
+func (s *Session) GetOrgContext(ctx context.Context, orgID int) (org Org, err error) {
+	reqURL := fmt.Sprintf("%s/api/orgs/%d", s.url, orgID)
+	err = s.doJSON(ctx, "GET", reqURL, nil, &org)
+	return
+}
+
+// GetOrgByName get a existing organization by name.
+// It returns a error matching ErrNotFound if the organization doesn't exist.
+func (s *Session) GetOrgByName(name string) (org Org, err error) {
+	return s.GetOrgByNameContext(context.Background(), name)
+}
+
+// GetOrgByNameContext is like GetOrgByName but uses the provided context for the request.
+func (s *Session) GetOrgByNameContext(ctx context.Context, name string) (org Org, err error) {
+	reqURL := s.url + "/api/orgs/name/" + neturl.PathEscape(name)
+	err = s.doJSON(ctx, "GET", reqURL, nil, &org)
+	return
+}
+
+// CreateOrg creates a Grafana organization.
+// It returns the organization with the ID assigned by Grafana.
+func (s *Session) CreateOrg(org Org) (Org, error) {
+	return s.CreateOrgContext(context.Background(), org)
+}
+
+// CreateOrgContext is like CreateOrg but uses the provided context for the request.
+func (s *Session) CreateOrgContext(ctx context.Context, org Org) (Org, error) {
+	reqURL := s.url + "/api/orgs"
+
+	var result struct {
+		OrgID int `json:"orgId"`
+	}
+	if err := s.doJSON(ctx, "POST", reqURL, org, &result); err != nil {
+		return org, err
+	}
+	org.ID = result.OrgID
+	return org, nil
+}
+
+// UpdateOrg updates the name and address of a existing organization.
+func (s *Session) UpdateOrg(org Org) (err error) {
+	return s.UpdateOrgContext(context.Background(), org)
+}
+
+// UpdateOrgContext is like UpdateOrg but uses the provided context for the request.
+func (s *Session) UpdateOrgContext(ctx context.Context, org Org) (err error) {
+	reqURL := fmt.Sprintf("%s/api/orgs/%d", s.url, org.ID)
+	if err = s.doJSON(ctx, "PUT", reqURL, org, nil); err != nil {
+		return
+	}
+	return s.doJSON(ctx, "PUT", reqURL+"/address", org.Address, nil)
+}
+
+// DeleteOrg deletes a organization and all its dashboards and datasources.
+func (s *Session) DeleteOrg(orgID int) (err error) {
+	return s.DeleteOrgContext(context.Background(), orgID)
+}
+
+// DeleteOrgContext is like DeleteOrg but uses the provided context for the request.
+func (s *Session) DeleteOrgContext(ctx context.Context, orgID int) (err error) {
+	reqURL := fmt.Sprintf("%s/api/orgs/%d", s.url, orgID)
+	return s.doJSON(ctx, "DELETE", reqURL, nil, nil)
+}
+
+// GetOrgUsers return the users member of a organization.
+func (s *Session) GetOrgUsers(orgID int) (users []OrgUser, err error) {
+	return s.GetOrgUsersContext(context.Background(), orgID)
+}
+
+// GetOrgUsersContext is like GetOrgUsers but uses the provided context for the request.
+func (s *Session) GetOrgUsersContext(ctx context.Context, orgID int) (users []OrgUser, err error) {
+	reqURL := fmt.Sprintf("%s/api/orgs/%d/users", s.url, orgID)
+	err = s.doJSON(ctx, "GET", reqURL, nil, &users)
+	return
+}
+
+// AddOrgUser adds a existing user to a organization with the given role.
+// The user is identified by its login or email.
+func (s *Session) AddOrgUser(orgID int, loginOrEmail string, role string) (err error) {
+	return s.AddOrgUserContext(context.Background(), orgID, loginOrEmail, role)
+}
+
+// AddOrgUserContext is like AddOrgUser but uses the provided context for the request.
+func (s *Session) AddOrgUserContext(ctx context.Context, orgID int, loginOrEmail string, role string) (err error) {
+	reqURL := fmt.Sprintf("%s/api/orgs/%d/users", s.url, orgID)
+	content := map[string]string{"loginOrEmail": loginOrEmail, "role": role}
+	return s.doJSON(ctx, "POST", reqURL, content, nil)
+}
+
+// UpdateOrgUser changes the role of a user in a organization.
+func (s *Session) UpdateOrgUser(orgID int, userID int, role string) (err error) {
+	return s.UpdateOrgUserContext(context.Background(), orgID, userID, role)
+}
+
+// UpdateOrgUserContext is like UpdateOrgUser but uses the provided context for the request.
+func (s *Session) UpdateOrgUserContext(ctx context.Context, orgID int, userID int, role string) (err error) {
+	reqURL := fmt.Sprintf("%s/api/orgs/%d/users/%d", s.url, orgID, userID)
+	content := map[string]string{"role": role}
+	return s.doJSON(ctx, "PATCH", reqURL, content, nil)
+}
+
+// RemoveOrgUser removes a user from a organization.
+func (s *Session) RemoveOrgUser(orgID int, userID int) (err error) {
+	return s.RemoveOrgUserContext(context.Background(), orgID, userID)
+}
+
+// RemoveOrgUserContext is like RemoveOrgUser but uses the provided context for the request.
+func (s *Session) RemoveOrgUserContext(ctx context.Context, orgID int, userID int) (err error) {
+	reqURL := fmt.Sprintf("%s/api/orgs/%d/users/%d", s.url, orgID, userID)
+	return s.doJSON(ctx, "DELETE", reqURL, nil, nil)
+}
@@ -0,0 +1,85 @@
+package grafanaclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ForOrg(t *testing.T) {
+	var orgHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgHeader = r.Header.Get("X-Grafana-Org-Id")
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	session := NewTokenSession("secret", srv.URL)
+	scoped := session.ForOrg(3)
+
+	_, err := scoped.GetDataSourceList()
+	assert.Nil(t, err, "We are expecting no error and got one getting DataSource")
+	assert.Equal(t, "3", orgHeader, "We are expecting the org header on the scoped session")
+	assert.Equal(t, 3, scoped.OrgID())
+
+	_, err = session.GetDataSourceList()
+	assert.Nil(t, err, "We are expecting no error and got one getting DataSource")
+	assert.Equal(t, "", orgHeader, "We are not expecting the org header on the parent session")
+}
+
+func Test_OrgLifecycle(t *testing.T) {
+	var calls []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/orgs", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		var org Org
+		json.NewDecoder(r.Body).Decode(&org)
+		assert.Equal(t, "team-a", org.Name)
+		w.Write([]byte(`{"orgId":7,"message":"Organization created"}`))
+	})
+	mux.HandleFunc("/api/orgs/7/users", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if r.Method == "GET" {
+			w.Write([]byte(`[{"orgId":7,"userId":2,"login":"bob","role":"Editor"}]`))
+			return
+		}
+		var content map[string]string
+		json.NewDecoder(r.Body).Decode(&content)
+		assert.Equal(t, "bob", content["loginOrEmail"])
+		assert.Equal(t, RoleEditor, content["role"])
+	})
+	mux.HandleFunc("/api/orgs/7/users/2", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+	})
+	mux.HandleFunc("/api/user/using/7", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	session := NewTokenSession("secret", srv.URL)
+
+	org, err := session.CreateOrg(Org{Name: "team-a"})
+	assert.Nil(t, err, "We are expecting no error and got one when creating Org")
+	assert.Equal(t, 7, org.ID, "We are expecting the assigned org ID")
+
+	assert.Nil(t, session.AddOrgUser(org.ID, "bob", RoleEditor))
+	users, err := session.GetOrgUsers(org.ID)
+	assert.Nil(t, err, "We are expecting no error and got one when getting Org users")
+	assert.Equal(t, "bob", users[0].Login)
+	assert.Nil(t, session.UpdateOrgUser(org.ID, 2, RoleAdmin))
+	assert.Nil(t, session.RemoveOrgUser(org.ID, 2))
+	assert.Nil(t, session.SwitchOrg(org.ID))
+
+	assert.Equal(t, []string{
+		"POST /api/orgs",
+		"POST /api/orgs/7/users",
+		"GET /api/orgs/7/users",
+		"PATCH /api/orgs/7/users/2",
+		"DELETE /api/orgs/7/users/2",
+		"POST /api/user/using/7",
+	}, calls)
+}
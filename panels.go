@@ -0,0 +1,455 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"encoding/json"
+)
+
+// Panel types
+const (
+	PanelGraph      = "graph"
+	PanelRow        = "row"
+	PanelTimeseries = "timeseries"
+	PanelStat       = "stat"
+	PanelGauge      = "gauge"
+	PanelBarGauge   = "bargauge"
+	PanelTable      = "table"
+	PanelText       = "text"
+	PanelHeatmap    = "heatmap"
+	PanelLogs       = "logs"
+	PanelSinglestat = "singlestat"
+)
+
+// A FieldConfig contains the field settings of the panels introduced with Grafana 7.0.
+// Defaults apply to every field, Overrides to the fields matched by their matcher.
+type FieldConfig struct {
+	Defaults  FieldDefaults              `json:"defaults"`
+	Overrides []interface{}              `json:"overrides,omitempty"`
+	Extra     map[string]json.RawMessage `json:"-" toml:"-"`
+	absent    map[string]bool
+}
+
+// A FieldDefaults contains the default field settings of a panel.
+type FieldDefaults struct {
+	Unit       string                     `json:"unit,omitempty"`
+	Min        *float64                   `json:"min,omitempty"`
+	Max        *float64                   `json:"max,omitempty"`
+	Decimals   *int                       `json:"decimals,omitempty"`
+	Thresholds *Thresholds                `json:"thresholds,omitempty"`
+	Mappings   []ValueMapping             `json:"mappings,omitempty"`
+	Color      map[string]interface{}     `json:"color,omitempty"`
+	Custom     map[string]interface{}     `json:"custom,omitempty"`
+	Extra      map[string]json.RawMessage `json:"-" toml:"-"`
+	absent     map[string]bool
+}
+
+// Modes of Thresholds
+const (
+	ThresholdsAbsolute   = "absolute"
+	ThresholdsPercentage = "percentage"
+)
+
+// Thresholds contains the steps coloring a value.
+// The first step is the base one and has no value.
+type Thresholds struct {
+	Mode   string                     `json:"mode"`
+	Steps  []ThresholdStep            `json:"steps,omitempty"`
+	Extra  map[string]json.RawMessage `json:"-" toml:"-"`
+	absent map[string]bool
+}
+
+// A ThresholdStep sets the color of the values above Value.
+type ThresholdStep struct {
+	Color  string                     `json:"color"`
+	Value  *float64                   `json:"value"`
+	Extra  map[string]json.RawMessage `json:"-" toml:"-"`
+	absent map[string]bool
+}
+
+// Types of ValueMapping
+const (
+	MappingValue   = "value"
+	MappingRange   = "range"
+	MappingRegex   = "regex"
+	MappingSpecial = "special"
+)
+
+// A ValueMapping maps values to a text or a color.
+// Type is value, range, regex or special, Options depends on the type, like
+// {"1": {"text": "up", "color": "green"}} for a value mapping.
+// The mappings of Grafana 7.x have a numeric Type, 1 for value and 2 for range,
+// and their settings, like value and text, are kept in Extra.
+type ValueMapping struct {
+	Type    interface{}                `json:"type"`
+	Options map[string]interface{}     `json:"options"`
+	Extra   map[string]json.RawMessage `json:"-" toml:"-"`
+	absent  map[string]bool
+}
+
+// NewThresholds create thresholds with a green base step and a step per value,
+// colored with the given colors.
+func NewThresholds(values []float64, colors []string) *Thresholds {
+	thresholds := &Thresholds{Mode: ThresholdsAbsolute, Steps: []ThresholdStep{{Color: "green"}}}
+	for i := range values {
+		value := values[i]
+		color := "red"
+		if i < len(colors) {
+			color = colors[i]
+		}
+		thresholds.Steps = append(thresholds.Steps, ThresholdStep{Color: color, Value: &value})
+	}
+	return thresholds
+}
+
+// NewFieldConfig create a field config with the Grafana default thresholds: green, and red above 80.
+func NewFieldConfig() *FieldConfig {
+	return &FieldConfig{Defaults: FieldDefaults{Thresholds: NewThresholds([]float64{80}, []string{"red"})}}
+}
+
+// SetUnit sets the unit of the panel fields, like percent or bytes.
+func (panel *Panel) SetUnit(unit string) {
+	panel.fieldDefaults().Unit = unit
+}
+
+// SetRange sets the minimum and maximum values of the panel fields.
+func (panel *Panel) SetRange(min float64, max float64) {
+	defaults := panel.fieldDefaults()
+	defaults.Min = &min
+	defaults.Max = &max
+}
+
+// SetDecimals sets the number of decimals displayed for the panel fields.
+func (panel *Panel) SetDecimals(decimals int) {
+	panel.fieldDefaults().Decimals = &decimals
+}
+
+// SetThresholds sets the thresholds of the panel fields.
+func (panel *Panel) SetThresholds(thresholds *Thresholds) {
+	panel.fieldDefaults().Thresholds = thresholds
+}
+
+// AddMapping adds a value mapping to the panel fields.
+func (panel *Panel) AddMapping(mapping ValueMapping) {
+	defaults := panel.fieldDefaults()
+	defaults.Mappings = append(defaults.Mappings, mapping)
+}
+
+// fieldDefaults returns the field defaults of the panel, creating the field config if needed.
+func (panel *Panel) fieldDefaults() *FieldDefaults {
+	if panel.FieldConfig == nil {
+		panel.FieldConfig = NewFieldConfig()
+	}
+	return &panel.FieldConfig.Defaults
+}
+
+// A ReduceOptions defines how the values of a field are reduced to a single value.
+type ReduceOptions struct {
+	Values bool     `json:"values"`
+	Calcs  []string `json:"calcs"`
+	Fields string   `json:"fields"`
+}
+
+// NewReduceOptions create reduce options keeping the last not null value.
+func NewReduceOptions() ReduceOptions {
+	return ReduceOptions{Calcs: []string{"lastNotNull"}}
+}
+
+// A VizLegend contains the legend options of the timeseries panel.
+type VizLegend struct {
+	ShowLegend  bool     `json:"showLegend"`
+	DisplayMode string   `json:"displayMode"`
+	Placement   string   `json:"placement"`
+	Calcs       []string `json:"calcs"`
+}
+
+// A VizTooltip contains the tooltip options of the timeseries panel.
+type VizTooltip struct {
+	Mode string `json:"mode"`
+	Sort string `json:"sort"`
+}
+
+// TimeseriesOptions contains the options of the timeseries panel.
+type TimeseriesOptions struct {
+	Legend  VizLegend  `json:"legend"`
+	Tooltip VizTooltip `json:"tooltip"`
+}
+
+// NewTimeseriesOptions create the default timeseries panel options.
+func NewTimeseriesOptions() TimeseriesOptions {
+	return TimeseriesOptions{
+		Legend:  VizLegend{ShowLegend: true, DisplayMode: "list", Placement: "bottom", Calcs: []string{}},
+		Tooltip: VizTooltip{Mode: "single", Sort: "none"},
+	}
+}
+
+// StatOptions contains the options of the stat panel.
+type StatOptions struct {
+	ReduceOptions ReduceOptions `json:"reduceOptions"`
+	ColorMode     string        `json:"colorMode"`
+	GraphMode     string        `json:"graphMode"`
+	JustifyMode   string        `json:"justifyMode"`
+	TextMode      string        `json:"textMode"`
+	Orientation   string        `json:"orientation"`
+}
+
+// NewStatOptions create the default stat panel options.
+func NewStatOptions() StatOptions {
+	return StatOptions{
+		ReduceOptions: NewReduceOptions(),
+		ColorMode:     "value",
+		GraphMode:     "area",
+		JustifyMode:   "auto",
+		TextMode:      "auto",
+		Orientation:   "auto",
+	}
+}
+
+// GaugeOptions contains the options of the gauge panel.
+type GaugeOptions struct {
+	ReduceOptions        ReduceOptions `json:"reduceOptions"`
+	Orientation          string        `json:"orientation"`
+	ShowThresholdLabels  bool          `json:"showThresholdLabels"`
+	ShowThresholdMarkers bool          `json:"showThresholdMarkers"`
+}
+
+// NewGaugeOptions create the default gauge panel options.
+func NewGaugeOptions() GaugeOptions {
+	return GaugeOptions{ReduceOptions: NewReduceOptions(), Orientation: "auto", ShowThresholdMarkers: true}
+}
+
+// BarGaugeOptions contains the options of the bar gauge panel.
+type BarGaugeOptions struct {
+	ReduceOptions ReduceOptions `json:"reduceOptions"`
+	Orientation   string        `json:"orientation"`
+	DisplayMode   string        `json:"displayMode"`
+	ShowUnfilled  bool          `json:"showUnfilled"`
+}
+
+// NewBarGaugeOptions create the default bar gauge panel options.
+func NewBarGaugeOptions() BarGaugeOptions {
+	return BarGaugeOptions{ReduceOptions: NewReduceOptions(), Orientation: "auto", DisplayMode: "gradient", ShowUnfilled: true}
+}
+
+// A TableFooter contains the footer options of the table panel.
+type TableFooter struct {
+	Show    bool     `json:"show"`
+	Reducer []string `json:"reducer"`
+}
+
+// TableOptions contains the options of the table panel.
+type TableOptions struct {
+	ShowHeader bool        `json:"showHeader"`
+	CellHeight string      `json:"cellHeight,omitempty"`
+	Footer     TableFooter `json:"footer"`
+}
+
+// NewTableOptions create the default table panel options.
+func NewTableOptions() TableOptions {
+	return TableOptions{ShowHeader: true, CellHeight: "sm", Footer: TableFooter{Reducer: []string{"sum"}}}
+}
+
+// TextOptions contains the options of the text panel.
+// Mode is markdown or html.
+type TextOptions struct {
+	Mode    string `json:"mode"`
+	Content string `json:"content"`
+}
+
+// A HeatmapColor contains the color scheme of the heatmap panel.
+type HeatmapColor struct {
+	Mode   string `json:"mode"`
+	Scheme string `json:"scheme"`
+	Steps  int    `json:"steps"`
+}
+
+// HeatmapOptions contains the options of the heatmap panel of Grafana 9.0 and later.
+// Calculate builds the buckets from the raw values, it's false when the query returns buckets.
+type HeatmapOptions struct {
+	Calculate bool         `json:"calculate"`
+	CellGap   int          `json:"cellGap"`
+	Color     HeatmapColor `json:"color"`
+	Legend    struct {
+		Show bool `json:"show"`
+	} `json:"legend"`
+	Tooltip struct {
+		Show       bool `json:"show"`
+		YHistogram bool `json:"yHistogram"`
+	} `json:"tooltip"`
+}
+
+// NewHeatmapOptions create the default heatmap panel options.
+func NewHeatmapOptions() HeatmapOptions {
+	options := HeatmapOptions{CellGap: 1, Color: HeatmapColor{Mode: "scheme", Scheme: "Oranges", Steps: 64}}
+	options.Legend.Show = true
+	options.Tooltip.Show = true
+	return options
+}
+
+// LogsOptions contains the options of the logs panel.
+type LogsOptions struct {
+	ShowTime           bool   `json:"showTime"`
+	ShowLabels         bool   `json:"showLabels"`
+	ShowCommonLabels   bool   `json:"showCommonLabels"`
+	WrapLogMessage     bool   `json:"wrapLogMessage"`
+	PrettifyLogMessage bool   `json:"prettifyLogMessage"`
+	EnableLogDetails   bool   `json:"enableLogDetails"`
+	DedupStrategy      string `json:"dedupStrategy"`
+	SortOrder          string `json:"sortOrder"`
+}
+
+// NewLogsOptions create the default logs panel options.
+func NewLogsOptions() LogsOptions {
+	return LogsOptions{ShowTime: true, EnableLogDetails: true, DedupStrategy: "none", SortOrder: "Descending"}
+}
+
+// SinglestatOptions contains the options of the singlestat panel, replaced by the stat panel in Grafana 7.0.
+// Thresholds is a comma separated list of values, like "50,80", and Colors has one more color than thresholds.
+type SinglestatOptions struct {
+	ValueName       string   `json:"-"`
+	Format          string   `json:"format"`
+	Prefix          string   `json:"prefix"`
+	Postfix         string   `json:"postfix"`
+	Decimals        *int     `json:"decimals"`
+	Thresholds      string   `json:"thresholds"`
+	Colors          []string `json:"colors"`
+	ColorValue      bool     `json:"colorValue"`
+	ColorBackground bool     `json:"colorBackground"`
+	Sparkline       struct {
+		Show bool `json:"show"`
+		Full bool `json:"full"`
+	} `json:"sparkline"`
+	Gauge struct {
+		Show             bool    `json:"show"`
+		MinValue         float64 `json:"minValue"`
+		MaxValue         float64 `json:"maxValue"`
+		ThresholdMarkers bool    `json:"thresholdMarkers"`
+		ThresholdLabels  bool    `json:"thresholdLabels"`
+	} `json:"gauge"`
+}
+
+// NewSinglestatOptions create the default singlestat panel options.
+func NewSinglestatOptions() SinglestatOptions {
+	options := SinglestatOptions{
+		ValueName: "avg",
+		Format:    "none",
+		Colors:    []string{"#299c46", "rgba(237, 129, 40, 0.89)", "#d44a3a"},
+	}
+	options.Gauge.MaxValue = 100
+	options.Gauge.ThresholdMarkers = true
+	return options
+}
+
+// graphFields are the Panel fields of the graph panel, not encoded for the other panel types
+// until they are set.
+var graphFields = map[string]bool{
+	"content": true,
+	"mode":    true,
+	"error":   true,
+	"span":    true,
+	"style":   true,
+	"fill":    true,
+	"stack":   true,
+	"targets": true,
+	"tooltip": true,
+	"legend":  true,
+}
+
+// newTypedPanel create a panel of the given type, with no legacy graph settings.
+func newTypedPanel(panelType string, title string, options interface{}) Panel {
+	return Panel{
+		Type:     panelType,
+		Title:    title,
+		Editable: true,
+		Options:  options,
+		absent:   graphFields,
+	}
+}
+
+// NewTimeseriesPanel create a timeseries panel, the graph panel of Grafana 7.4 and later.
+func NewTimeseriesPanel(title string, options TimeseriesOptions) Panel {
+	panel := newTypedPanel(PanelTimeseries, title, options)
+	panel.FieldConfig = NewFieldConfig()
+	return panel
+}
+
+// NewStatPanel create a stat panel, displaying a single value.
+func NewStatPanel(title string, options StatOptions) Panel {
+	panel := newTypedPanel(PanelStat, title, options)
+	panel.FieldConfig = NewFieldConfig()
+	return panel
+}
+
+// NewGaugePanel create a gauge panel.
+func NewGaugePanel(title string, options GaugeOptions) Panel {
+	panel := newTypedPanel(PanelGauge, title, options)
+	panel.FieldConfig = NewFieldConfig()
+	return panel
+}
+
+// NewBarGaugePanel create a bar gauge panel.
+func NewBarGaugePanel(title string, options BarGaugeOptions) Panel {
+	panel := newTypedPanel(PanelBarGauge, title, options)
+	panel.FieldConfig = NewFieldConfig()
+	return panel
+}
+
+// NewTablePanel create a table panel.
+func NewTablePanel(title string, options TableOptions) Panel {
+	panel := newTypedPanel(PanelTable, title, options)
+	panel.FieldConfig = NewFieldConfig()
+	return panel
+}
+
+// NewTextPanel create a text panel.
+// The content is also set in the Content and Mode fields used before Grafana 7.1.
+func NewTextPanel(title string, options TextOptions) Panel {
+	panel := newTypedPanel(PanelText, title, options)
+	panel.Content = options.Content
+	panel.Mode = options.Mode
+	return panel
+}
+
+// NewHeatmapPanel create a heatmap panel.
+func NewHeatmapPanel(title string, options HeatmapOptions) Panel {
+	panel := newTypedPanel(PanelHeatmap, title, options)
+	panel.FieldConfig = &FieldConfig{}
+	return panel
+}
+
+// NewLogsPanel create a logs panel, used with a logs datasource like Loki.
+func NewLogsPanel(title string, options LogsOptions) Panel {
+	return newTypedPanel(PanelLogs, title, options)
+}
+
+// NewSinglestatPanel create a singlestat panel, for Grafana servers older than 7.0.
+// The singlestat settings are top level panel fields, they are stored in Extra.
+// It returns a error if the options cannot be encoded.
+func NewSinglestatPanel(title string, options SinglestatOptions) (panel Panel, err error) {
+	panel = newTypedPanel(PanelSinglestat, title, nil)
+	panel.ValueName = options.ValueName
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		return
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return
+	}
+	for key, value := range fields {
+		panel.Extra = setExtra(panel.Extra, key, value)
+	}
+	return
+}
@@ -0,0 +1,126 @@
+package grafanaclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// encodePanel returns the json object of a panel.
+func encodePanel(t *testing.T, panel Panel) map[string]interface{} {
+	data, err := json.Marshal(panel)
+	assert.Nil(t, err, "We are expecting no error and got one encoding panel")
+	var encoded map[string]interface{}
+	json.Unmarshal(data, &encoded)
+	return encoded
+}
+
+func Test_StatPanel(t *testing.T) {
+	panel := NewStatPanel("CPU", NewStatOptions())
+	panel.SetUnit("percent")
+	panel.SetRange(0, 100)
+	panel.SetDecimals(1)
+	panel.SetThresholds(NewThresholds([]float64{70, 90}, []string{"orange", "red"}))
+	panel.AddMapping(ValueMapping{Type: "value", Options: map[string]interface{}{"0": map[string]interface{}{"text": "idle"}}})
+
+	encoded := encodePanel(t, panel)
+	assert.Equal(t, PanelStat, encoded["type"])
+	defaults := encoded["fieldConfig"].(map[string]interface{})["defaults"].(map[string]interface{})
+	assert.Equal(t, "percent", defaults["unit"])
+	assert.Equal(t, 0.0, defaults["min"])
+	assert.Equal(t, 100.0, defaults["max"])
+	assert.Equal(t, 1.0, defaults["decimals"])
+	assert.Len(t, defaults["mappings"], 1)
+
+	thresholds := defaults["thresholds"].(map[string]interface{})
+	assert.Equal(t, ThresholdsAbsolute, thresholds["mode"])
+	steps := thresholds["steps"].([]interface{})
+	assert.Len(t, steps, 3)
+	assert.Equal(t, map[string]interface{}{"color": "green", "value": nil}, steps[0], "We are expecting a base step without value")
+	assert.Equal(t, map[string]interface{}{"color": "red", "value": 90.0}, steps[2])
+
+	options := encoded["options"].(map[string]interface{})
+	assert.Equal(t, "area", options["graphMode"])
+	assert.Equal(t, []interface{}{"lastNotNull"}, options["reduceOptions"].(map[string]interface{})["calcs"])
+}
+
+func Test_TypedPanels(t *testing.T) {
+	panels := []Panel{
+		NewTimeseriesPanel("load", NewTimeseriesOptions()),
+		NewGaugePanel("memory", NewGaugeOptions()),
+		NewBarGaugePanel("disks", NewBarGaugeOptions()),
+		NewTablePanel("processes", NewTableOptions()),
+		NewTextPanel("notes", TextOptions{Mode: "markdown", Content: "# nmon"}),
+		NewHeatmapPanel("latency", NewHeatmapOptions()),
+		NewLogsPanel("syslog", NewLogsOptions()),
+	}
+	types := []string{PanelTimeseries, PanelGauge, PanelBarGauge, PanelTable, PanelText, PanelHeatmap, PanelLogs}
+	for i, panel := range panels {
+		encoded := encodePanel(t, panel)
+		assert.Equal(t, types[i], encoded["type"])
+		assert.Contains(t, encoded, "options", "We are expecting options for a %s panel", types[i])
+		for _, key := range []string{"legend", "tooltip", "span", "targets", "fill", "style"} {
+			assert.NotContains(t, encoded, key, "We are expecting no graph field for a %s panel", types[i])
+		}
+	}
+
+	encoded := encodePanel(t, panels[0])
+	legend := encoded["options"].(map[string]interface{})["legend"].(map[string]interface{})
+	assert.Equal(t, true, legend["showLegend"])
+	assert.NotContains(t, encodePanel(t, panels[6]), "fieldConfig")
+
+	text := encodePanel(t, panels[4])
+	assert.Equal(t, "# nmon", text["content"], "We are expecting the content for old Grafana servers")
+	assert.Equal(t, "# nmon", text["options"].(map[string]interface{})["content"])
+}
+
+func Test_SinglestatPanel(t *testing.T) {
+	options := NewSinglestatOptions()
+	options.Format = "percent"
+	options.Thresholds = "70,90"
+	options.Gauge.Show = true
+	panel, err := NewSinglestatPanel("CPU", options)
+	assert.Nil(t, err, "We are expecting no error and got one creating singlestat panel")
+
+	encoded := encodePanel(t, panel)
+	assert.Equal(t, PanelSinglestat, encoded["type"])
+	assert.Equal(t, "avg", encoded["valueName"])
+	assert.Equal(t, "percent", encoded["format"])
+	assert.Equal(t, "70,90", encoded["thresholds"])
+	assert.Len(t, encoded["colors"], 3)
+	assert.Equal(t, true, encoded["gauge"].(map[string]interface{})["show"])
+	assert.NotContains(t, encoded, "options")
+	assert.NotContains(t, encoded, "fieldConfig")
+}
+
+func Test_FieldConfigRoundTrip(t *testing.T) {
+	data := `{"defaults":{"unit":"bytes","links":[{"title":"host"}],
+			"thresholds":{"mode":"percentage","steps":[{"color":"green","value":null,"index":0},{"color":"red","value":80,"index":1}]},
+			"mappings":[{"id":0,"type":1,"value":"1","text":"up","op":"="},{"type":"value","options":{"0":{"text":"down"}}}]},
+		"overrides":[{"matcher":{"id":"byName","options":"rx"}}]}`
+	var config FieldConfig
+	err := json.Unmarshal([]byte(data), &config)
+	assert.Nil(t, err, "We are expecting no error and got one decoding field config")
+	assert.Equal(t, "bytes", config.Defaults.Unit)
+	assert.Equal(t, ThresholdsPercentage, config.Defaults.Thresholds.Mode)
+	assert.Contains(t, config.Defaults.Extra, "links")
+	assert.Equal(t, json.RawMessage("1"), config.Defaults.Thresholds.Steps[1].Extra["index"])
+	assert.Equal(t, float64(1), config.Defaults.Mappings[0].Type, "We are expecting the numeric type of Grafana 7.x")
+	assert.Equal(t, MappingValue, config.Defaults.Mappings[1].Type)
+
+	encoded, err := json.Marshal(config)
+	assert.Nil(t, err, "We are expecting no error and got one encoding field config")
+	assert.JSONEq(t, data, string(encoded))
+}
+
+func Test_TypedPanelSetFields(t *testing.T) {
+	panel := NewStatPanel("CPU", NewStatOptions())
+	panel.Span = 4
+	panel.AddTarget(NewTarget())
+
+	encoded := encodePanel(t, panel)
+	assert.Equal(t, float64(4), encoded["span"], "We are expecting a set span to be encoded")
+	assert.Len(t, encoded["targets"], 1)
+	assert.NotContains(t, encoded, "legend")
+}
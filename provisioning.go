@@ -0,0 +1,328 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A ProvisioningFile contains the structure of a Grafana datasources provisioning file.
+type ProvisioningFile struct {
+	APIVersion        int                     `yaml:"apiVersion"`
+	DeleteDatasources []ProvisioningDeletion  `yaml:"deleteDatasources,omitempty"`
+	Datasources       []ProvisionedDataSource `yaml:"datasources"`
+}
+
+// A ProvisioningDeletion identifies a datasource to delete in a provisioning file.
+type ProvisioningDeletion struct {
+	Name  string `yaml:"name"`
+	OrgID int    `yaml:"orgId,omitempty"`
+}
+
+// A ProvisionedDataSource contains the structure of a datasource in a provisioning file.
+type ProvisionedDataSource struct {
+	Name              string         `yaml:"name"`
+	Type              string         `yaml:"type"`
+	Access            string         `yaml:"access,omitempty"`
+	OrgID             int            `yaml:"orgId,omitempty"`
+	UID               string         `yaml:"uid,omitempty"`
+	URL               string         `yaml:"url,omitempty"`
+	User              string         `yaml:"user,omitempty"`
+	Password          string         `yaml:"password,omitempty"`
+	Database          string         `yaml:"database,omitempty"`
+	BasicAuth         bool           `yaml:"basicAuth,omitempty"`
+	BasicAuthUser     string         `yaml:"basicAuthUser,omitempty"`
+	BasicAuthPassword string         `yaml:"basicAuthPassword,omitempty"`
+	WithCredentials   bool           `yaml:"withCredentials,omitempty"`
+	IsDefault         bool           `yaml:"isDefault,omitempty"`
+	JSONData          JSONData       `yaml:"jsonData,omitempty"`
+	SecureJSONData    SecureJSONData `yaml:"secureJsonData,omitempty"`
+	Version           int            `yaml:"version,omitempty"`
+	Editable          bool           `yaml:"editable,omitempty"`
+}
+
+// ParseProvisioning decodes a datasources provisioning file.
+// Like Grafana, environment variables written $VAR or ${VAR} are expanded in the values,
+// and $$ is a literal $.
+func ParseProvisioning(data []byte) (file ProvisioningFile, err error) {
+	var root yaml.Node
+	if err = yaml.Unmarshal(data, &root); err != nil {
+		return
+	}
+	expandNode(&root)
+	err = root.Decode(&file)
+	return
+}
+
+// expandNode expands the environment variables of the scalar values of a YAML node.
+// The unquoted values are resolved again, so a variable can also be used for a number or a boolean.
+func expandNode(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			expandNode(child)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			expandNode(node.Content[i])
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "$") {
+			return
+		}
+		node.Value = expandEnv(node.Value)
+		if node.Style == 0 {
+			node.Tag = ""
+		}
+	}
+}
+
+// expandEnv replaces $VAR and ${VAR} by the value of the environment variable, and $$ by $.
+func expandEnv(value string) string {
+	return os.Expand(value, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		return os.Getenv(name)
+	})
+}
+
+// LoadProvisioningFile reads and decodes a datasources provisioning file.
+func LoadProvisioningFile(path string) (file ProvisioningFile, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	return ParseProvisioning(data)
+}
+
+// DataSources converts the provisioned datasources to DataSource structs.
+func (file ProvisioningFile) DataSources() []DataSource {
+	sources := make([]DataSource, len(file.Datasources))
+	for i, p := range file.Datasources {
+		sources[i] = DataSource{
+			UID:               p.UID,
+			OrgID:             p.OrgID,
+			Name:              p.Name,
+			Type:              p.Type,
+			Access:            p.Access,
+			URL:               p.URL,
+			Password:          p.Password,
+			User:              p.User,
+			Database:          p.Database,
+			BasicAuth:         p.BasicAuth,
+			BasicAuthUser:     p.BasicAuthUser,
+			BasicAuthPassword: p.BasicAuthPassword,
+			WithCredentials:   p.WithCredentials,
+			IsDefault:         p.IsDefault,
+			JSONData:          p.JSONData,
+			SecureJSONData:    p.SecureJSONData,
+			ReadOnly:          !p.Editable,
+		}
+	}
+	return sources
+}
+
+// Marshal encodes the provisioning file in YAML.
+func (file ProvisioningFile) Marshal() ([]byte, error) {
+	return yaml.Marshal(file)
+}
+
+// NewProvisioningFile create a provisioning file describing the given datasources.
+// Secrets are never written: every secret set on a datasource is replaced by a
+// environment variable placeholder like ${GF_DS_NMON_PASSWORD}.
+func NewProvisioningFile(sources []DataSource) ProvisioningFile {
+	file := ProvisioningFile{APIVersion: 1}
+	for _, ds := range sources {
+		p := ProvisionedDataSource{
+			Name:            ds.Name,
+			Type:            ds.Type,
+			Access:          ds.Access,
+			OrgID:           ds.OrgID,
+			UID:             ds.UID,
+			URL:             ds.URL,
+			User:            ds.User,
+			Database:        ds.Database,
+			BasicAuth:       ds.BasicAuth,
+			BasicAuthUser:   ds.BasicAuthUser,
+			WithCredentials: ds.WithCredentials,
+			IsDefault:       ds.IsDefault,
+			JSONData:        ds.JSONData,
+			Editable:        !ds.ReadOnly,
+		}
+		if ds.Password != "" {
+			p.Password = secretPlaceholder(ds.Name, "password")
+		}
+		if ds.BasicAuthPassword != "" {
+			p.BasicAuthPassword = secretPlaceholder(ds.Name, "basicAuthPassword")
+		}
+
+		secrets := make(map[string]bool)
+		for key := range ds.SecureJSONData {
+			secrets[key] = true
+		}
+		for key, set := range ds.SecureJSONFields {
+			if set {
+				secrets[key] = true
+			}
+		}
+		if len(secrets) > 0 {
+			p.SecureJSONData = SecureJSONData{}
+			for key := range secrets {
+				p.SecureJSONData[key] = secretPlaceholder(ds.Name, key)
+			}
+		}
+		file.Datasources = append(file.Datasources, p)
+	}
+	return file
+}
+
+// secretPlaceholder returns the environment variable placeholder used for a datasource secret.
+// The variable name is made of the datasource name and the secret key, like GF_DS_NMON_BASIC_AUTH_PASSWORD.
+func secretPlaceholder(name string, key string) string {
+	return "${GF_DS_" + envName(name) + "_" + envName(key) + "}"
+}
+
+// envName converts a name to a environment variable name: camel case words are separated
+// and every character which is not a letter or a digit is replaced by a underscore.
+func envName(name string) string {
+	var b strings.Builder
+	prevLower := false
+	for _, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			if prevLower {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			prevLower = false
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteString(strings.ToUpper(string(r)))
+			prevLower = true
+		default:
+			b.WriteByte('_')
+			prevLower = false
+		}
+	}
+	return b.String()
+}
+
+// ExportProvisioning returns the datasources of the session organization as a provisioning file.
+// Each datasource is retrieved individually to know which secrets are set.
+func (s *Session) ExportProvisioning() (ProvisioningFile, error) {
+	return s.ExportProvisioningContext(context.Background())
+}
+
+// ExportProvisioningContext is like ExportProvisioning but uses the provided context for the requests.
+func (s *Session) ExportProvisioningContext(ctx context.Context) (file ProvisioningFile, err error) {
+	list, err := s.GetDataSourceListContext(ctx)
+	if err != nil {
+		return
+	}
+
+	sources := make([]DataSource, len(list))
+	for i, elem := range list {
+		if sources[i], err = s.GetDataSourceByIDContext(ctx, elem.ID); err != nil {
+			return
+		}
+	}
+	return NewProvisioningFile(sources), nil
+}
+
+// ApplyProvisioning pushes a provisioning file through the API.
+// The datasources listed in deleteDatasources are deleted, then the provisioned ones are
+// reconciled like ReconcileDataSources does. Each organization referenced by orgId is
+// handled with a session scoped to it, datasources without orgId use the session organization.
+//
+// It returns the computed plan, which is applied unless DryRun is set.
+func (s *Session) ApplyProvisioning(file ProvisioningFile, opts ReconcileOptions) (DataSourcePlan, error) {
+	return s.ApplyProvisioningContext(context.Background(), file, opts)
+}
+
+// ApplyProvisioningContext is like ApplyProvisioning but uses the provided context for the requests.
+func (s *Session) ApplyProvisioningContext(ctx context.Context, file ProvisioningFile, opts ReconcileOptions) (plan DataSourcePlan, err error) {
+	desired := make(map[int][]DataSource)
+	deletions := make(map[int][]string)
+	for _, ds := range file.DataSources() {
+		desired[ds.OrgID] = append(desired[ds.OrgID], ds)
+	}
+	for _, del := range file.DeleteDatasources {
+		deletions[del.OrgID] = append(deletions[del.OrgID], del.Name)
+	}
+
+	var orgs []int
+	for orgID := range desired {
+		orgs = append(orgs, orgID)
+	}
+	for orgID := range deletions {
+		if _, ok := desired[orgID]; !ok {
+			orgs = append(orgs, orgID)
+		}
+	}
+	sort.Ints(orgs)
+
+	for _, orgID := range orgs {
+		session := s
+		if orgID != 0 {
+			session = s.ForOrg(orgID)
+		}
+
+		current, err := session.GetDataSourceListContext(ctx)
+		if err != nil {
+			return plan, err
+		}
+		if err = session.getMatchedDataSources(ctx, current, desired[orgID]); err != nil {
+			return plan, err
+		}
+
+		orgPlan := PlanDataSources(current, desired[orgID], opts.Prune)
+		orgPlan.Changes = append(provisioningDeletions(current, deletions[orgID], orgPlan), orgPlan.Changes...)
+
+		if !opts.DryRun {
+			if err = session.ApplyDataSourcePlanContext(ctx, orgPlan); err != nil {
+				return plan, err
+			}
+		}
+		plan.Changes = append(plan.Changes, orgPlan.Changes...)
+		plan.Unchanged = append(plan.Unchanged, orgPlan.Unchanged...)
+	}
+	return
+}
+
+// provisioningDeletions returns the delete changes of the existing datasources listed in names
+// which are not already deleted or updated by the plan.
+func provisioningDeletions(current []DataSource, names []string, plan DataSourcePlan) (changes []DataSourceChange) {
+	planned := make(map[int]bool)
+	for _, change := range plan.Changes {
+		if change.Current != nil {
+			planned[change.Current.ID] = true
+		}
+	}
+
+	for _, name := range names {
+		for i := range current {
+			if current[i].Name == name && !planned[current[i].ID] {
+				planned[current[i].ID] = true
+				changes = append(changes, DataSourceChange{Action: ActionDelete, Name: name, Current: &current[i]})
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,154 @@
+package grafanaclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var provisioningYAML = `apiVersion: 1
+
+deleteDatasources:
+  - name: Graphite
+    orgId: 1
+
+datasources:
+  - name: nmon
+    type: influxdb
+    access: proxy
+    uid: nmon-uid
+    url: http://influx:8086
+    database: nmon
+    user: grafana
+    jsonData:
+      httpMode: GET
+      timeInterval: 10s
+    secureJsonData:
+      password: ${INFLUX_PASSWORD}
+    editable: true
+`
+
+func Test_ParseProvisioning(t *testing.T) {
+	os.Setenv("INFLUX_PASSWORD", "s3cr3t")
+	defer os.Unsetenv("INFLUX_PASSWORD")
+
+	file, err := ParseProvisioning([]byte(provisioningYAML))
+	assert.Nil(t, err, "We are expecting no error and got one parsing provisioning")
+	assert.Equal(t, 1, file.APIVersion)
+	assert.Equal(t, []ProvisioningDeletion{{Name: "Graphite", OrgID: 1}}, file.DeleteDatasources)
+
+	sources := file.DataSources()
+	assert.Len(t, sources, 1)
+	assert.Equal(t, "nmon-uid", sources[0].UID)
+	assert.Equal(t, "10s", sources[0].JSONData.String("timeInterval"))
+	assert.Equal(t, "s3cr3t", sources[0].SecureJSONData["password"], "We are expecting the environment variable to be expanded")
+	assert.False(t, sources[0].ReadOnly)
+}
+
+func Test_ParseProvisioningExpansion(t *testing.T) {
+	os.Setenv("INFLUX_URL", "http://influx:8086\nuser: root")
+	os.Setenv("INFLUX_ORG", "2")
+	defer os.Unsetenv("INFLUX_URL")
+	defer os.Unsetenv("INFLUX_ORG")
+
+	file, err := ParseProvisioning([]byte(`apiVersion: 1
+datasources:
+  - name: nmon
+    type: influxdb
+    orgId: $INFLUX_ORG
+    url: ${INFLUX_URL}
+    user: grafana
+    password: "pa$$word"
+    jsonData:
+      timeInterval: $$__interval
+`))
+	assert.Nil(t, err, "We are expecting no error and got one parsing provisioning")
+	p := file.Datasources[0]
+	assert.Equal(t, 2, p.OrgID, "We are expecting a variable to be usable as a number")
+	assert.Equal(t, "http://influx:8086\nuser: root", p.URL, "We are expecting the expanded value to stay a string")
+	assert.Equal(t, "grafana", p.User, "We are expecting the expanded value to keep the YAML structure")
+	assert.Equal(t, "pa$word", p.Password, "We are expecting $$ to be a literal $")
+	assert.Equal(t, "$__interval", p.JSONData.String("timeInterval"), "We are expecting $$ to be a literal $")
+}
+
+func Test_NewProvisioningFile(t *testing.T) {
+	source := NewPrometheusDataSource("My Prom", "http://prometheus:9090")
+	source.SetBasicAuth("admin", "pw")
+	source.SecureJSONFields = map[string]bool{"httpHeaderValue1": true}
+
+	out, err := NewProvisioningFile([]DataSource{source}).Marshal()
+	assert.Nil(t, err, "We are expecting no error and got one exporting provisioning")
+	text := string(out)
+	assert.True(t, strings.Contains(text, "basicAuthPassword: ${GF_DS_MY_PROM_BASIC_AUTH_PASSWORD}"), text)
+	assert.True(t, strings.Contains(text, "httpHeaderValue1: ${GF_DS_MY_PROM_HTTP_HEADER_VALUE1}"), text)
+	assert.False(t, strings.Contains(text, "pw"), "We are not expecting secrets in the export")
+
+	file, err := ParseProvisioning(out)
+	assert.Nil(t, err, "We are expecting the export to be a valid provisioning file")
+	assert.Equal(t, "http://prometheus:9090", file.Datasources[0].URL)
+}
+
+func Test_ApplyProvisioning(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Write([]byte(`[{"id":5,"name":"Graphite","type":"graphite"}]`))
+			return
+		}
+		calls = append(calls, r.Method+" "+r.URL.Path+" org="+r.Header.Get("X-Grafana-Org-Id"))
+		w.Write([]byte(`{"id":6}`))
+	}))
+	defer srv.Close()
+
+	file, err := ParseProvisioning([]byte(provisioningYAML))
+	assert.Nil(t, err, "We are expecting no error and got one parsing provisioning")
+
+	session := NewTokenSession("secret", srv.URL)
+	plan, err := session.ApplyProvisioning(file, ReconcileOptions{})
+	assert.Nil(t, err, "We are expecting no error and got one applying provisioning")
+	assert.Len(t, plan.Changes, 2)
+	assert.Equal(t, []string{
+		"POST /api/datasources org=",
+		"DELETE /api/datasources/5 org=1",
+	}, calls)
+}
+
+func Test_ApplyProvisioningDetails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/datasources":
+			// like Grafana, the list has no basicAuthUser nor secureJsonFields
+			w.Write([]byte(`[{"id":1,"uid":"a","name":"nmon","type":"influxdb","access":"proxy","url":"http://influx:8086","basicAuth":true}]`))
+		case "/api/datasources/1":
+			w.Write([]byte(`{"id":1,"uid":"a","name":"nmon","type":"influxdb","access":"proxy","url":"http://influx:8086","basicAuth":true,
+				"basicAuthUser":"admin","secureJsonFields":{"basicAuthPassword":true}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	file, err := ParseProvisioning([]byte(`apiVersion: 1
+datasources:
+  - name: nmon
+    type: influxdb
+    access: proxy
+    url: http://influx:8086
+    basicAuth: true
+    basicAuthUser: admin
+    secureJsonData:
+      basicAuthPassword: pw
+    editable: true
+`))
+	assert.Nil(t, err, "We are expecting no error and got one parsing provisioning")
+
+	session := NewTokenSession("secret", srv.URL)
+	plan, err := session.ApplyProvisioning(file, ReconcileOptions{})
+	assert.Nil(t, err, "We are expecting no error and got one applying provisioning")
+	assert.True(t, plan.Empty(), "We are expecting no change and got %s", plan.String())
+	assert.Equal(t, []string{"nmon"}, plan.Unchanged)
+}
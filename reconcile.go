@@ -0,0 +1,267 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+)
+
+// Actions of a DataSourceChange
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// ReconcileOptions defines how ReconcileDataSources brings Grafana into the desired state.
+type ReconcileOptions struct {
+	// DryRun only computes the plan, nothing is changed on the server.
+	DryRun bool
+	// Prune deletes the existing datasources which are not part of the desired state.
+	Prune bool
+}
+
+// A DataSourceChange describes a change needed to reach the desired state.
+// Fields lists the drifted fields of an update.
+type DataSourceChange struct {
+	Action  string      `json:"action"`
+	Name    string      `json:"name"`
+	Fields  []string    `json:"fields,omitempty"`
+	Current *DataSource `json:"current,omitempty"`
+	Desired *DataSource `json:"desired,omitempty"`
+}
+
+// A DataSourcePlan contains the changes computed by ReconcileDataSources.
+// Unchanged lists the names of the datasources already in the desired state.
+type DataSourcePlan struct {
+	Changes   []DataSourceChange `json:"changes"`
+	Unchanged []string           `json:"unchanged"`
+}
+
+// Empty reports if the plan has no change to apply.
+func (plan DataSourcePlan) Empty() bool {
+	return len(plan.Changes) == 0
+}
+
+// String returns a human readable summary of the plan, one line per change.
+func (plan DataSourcePlan) String() string {
+	var buf bytes.Buffer
+	for _, change := range plan.Changes {
+		fmt.Fprintf(&buf, "%s %s", change.Action, change.Name)
+		if len(change.Fields) > 0 {
+			fmt.Fprintf(&buf, " %v", change.Fields)
+		}
+		buf.WriteString("\n")
+	}
+	if buf.Len() == 0 {
+		return "no changes\n"
+	}
+	return buf.String()
+}
+
+// LoadDataSourcesFile reads a json file containing an array of DataSource.
+// It can be used as the desired state of ReconcileDataSources.
+func LoadDataSourcesFile(file string) (sources []DataSource, err error) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(buf, &sources)
+	return
+}
+
+// ReconcileDataSources brings the datasources of the session organization into the desired state.
+// A desired DataSource matches an existing one by UID when it has one, by name otherwise.
+// Missing datasources are created, drifted ones are updated and, with the Prune option,
+// unmanaged ones are deleted.
+// Secrets can't be read back from Grafana, so only the secrets not yet set are considered drifted.
+//
+// It returns the computed plan, which is applied unless DryRun is set.
+func (s *Session) ReconcileDataSources(desired []DataSource, opts ReconcileOptions) (DataSourcePlan, error) {
+	return s.ReconcileDataSourcesContext(context.Background(), desired, opts)
+}
+
+// ReconcileDataSourcesContext is like ReconcileDataSources but uses the provided context for the requests.
+func (s *Session) ReconcileDataSourcesContext(ctx context.Context, desired []DataSource, opts ReconcileOptions) (plan DataSourcePlan, err error) {
+	current, err := s.GetDataSourceListContext(ctx)
+	if err != nil {
+		return
+	}
+
+	if err = s.getMatchedDataSources(ctx, current, desired); err != nil {
+		return
+	}
+
+	plan = PlanDataSources(current, desired, opts.Prune)
+	if opts.DryRun {
+		return
+	}
+	err = s.ApplyDataSourcePlanContext(ctx, plan)
+	return
+}
+
+// getMatchedDataSources replaces the current datasources matching a desired one by their details.
+// The list leaves out basicAuthUser, withCredentials and secureJsonFields, so the matched
+// datasources are read one by one before being compared.
+func (s *Session) getMatchedDataSources(ctx context.Context, current []DataSource, desired []DataSource) (err error) {
+	fetched := make(map[int]bool)
+	for _, want := range desired {
+		existing := findDataSource(current, want)
+		if existing == nil || fetched[existing.ID] {
+			continue
+		}
+		if *existing, err = s.GetDataSourceByIDContext(ctx, existing.ID); err != nil {
+			return
+		}
+		fetched[existing.ID] = true
+	}
+	return
+}
+
+// PlanDataSources computes the changes needed to go from the current datasources to the desired ones.
+// With prune, the current datasources without desired counterpart are deleted.
+func PlanDataSources(current []DataSource, desired []DataSource, prune bool) (plan DataSourcePlan) {
+	matched := make(map[int]bool)
+
+	for i := range desired {
+		want := desired[i]
+		existing := findDataSource(current, want)
+		if existing == nil {
+			plan.Changes = append(plan.Changes, DataSourceChange{Action: ActionCreate, Name: want.Name, Desired: &want})
+			continue
+		}
+		matched[existing.ID] = true
+
+		fields := dataSourceDrift(*existing, want)
+		if len(fields) == 0 {
+			plan.Unchanged = append(plan.Unchanged, want.Name)
+			continue
+		}
+		want.ID = existing.ID
+		if want.UID == "" {
+			want.UID = existing.UID
+		}
+		plan.Changes = append(plan.Changes, DataSourceChange{Action: ActionUpdate, Name: want.Name, Fields: fields, Current: existing, Desired: &want})
+	}
+
+	if prune {
+		for i := range current {
+			if !matched[current[i].ID] {
+				plan.Changes = append(plan.Changes, DataSourceChange{Action: ActionDelete, Name: current[i].Name, Current: &current[i]})
+			}
+		}
+	}
+	return
+}
+
+// ApplyDataSourcePlan applies the changes of a plan: deletions first, then updates and creations.
+// It stops at the first error.
+func (s *Session) ApplyDataSourcePlan(plan DataSourcePlan) error {
+	return s.ApplyDataSourcePlanContext(context.Background(), plan)
+}
+
+// ApplyDataSourcePlanContext is like ApplyDataSourcePlan but uses the provided context for the requests.
+func (s *Session) ApplyDataSourcePlanContext(ctx context.Context, plan DataSourcePlan) (err error) {
+	for _, action := range []string{ActionDelete, ActionUpdate, ActionCreate} {
+		for _, change := range plan.Changes {
+			if change.Action != action {
+				continue
+			}
+			switch action {
+			case ActionDelete:
+				err = s.DeleteDataSourceContext(ctx, *change.Current)
+			case ActionUpdate:
+				_, err = s.UpdateDataSourceContext(ctx, *change.Desired)
+			case ActionCreate:
+				_, err = s.CreateDataSourceContext(ctx, *change.Desired)
+			}
+			if err != nil {
+				return fmt.Errorf("unable to %s datasource %s: %w", action, change.Name, err)
+			}
+		}
+	}
+	return
+}
+
+// findDataSource returns the datasource matching the desired one by UID, or by name.
+func findDataSource(current []DataSource, desired DataSource) *DataSource {
+	for i := range current {
+		if desired.UID != "" && current[i].UID == desired.UID {
+			return &current[i]
+		}
+	}
+	if desired.UID != "" {
+		return nil
+	}
+	for i := range current {
+		if current[i].Name == desired.Name {
+			return &current[i]
+		}
+	}
+	return nil
+}
+
+// dataSourceDrift returns the json names of the fields which differ between the current and desired datasource.
+func dataSourceDrift(current DataSource, desired DataSource) (fields []string) {
+	compare := []struct {
+		name          string
+		current, want interface{}
+	}{
+		{"name", current.Name, desired.Name},
+		{"type", current.Type, desired.Type},
+		{"access", current.Access, desired.Access},
+		{"url", current.URL, desired.URL},
+		{"user", current.User, desired.User},
+		{"database", current.Database, desired.Database},
+		{"basicAuth", current.BasicAuth, desired.BasicAuth},
+		{"basicAuthUser", current.BasicAuthUser, desired.BasicAuthUser},
+		{"withCredentials", current.WithCredentials, desired.WithCredentials},
+		{"isDefault", current.IsDefault, desired.IsDefault},
+	}
+	for _, field := range compare {
+		if field.current != field.want {
+			fields = append(fields, field.name)
+		}
+	}
+
+	if !reflect.DeepEqual(normalizeJSONData(current.JSONData), normalizeJSONData(desired.JSONData)) {
+		fields = append(fields, "jsonData")
+	}
+
+	var secrets []string
+	for key := range desired.SecureJSONData {
+		if !current.SecureJSONFields[key] {
+			secrets = append(secrets, "secureJsonData."+key)
+		}
+	}
+	sort.Strings(secrets)
+	return append(fields, secrets...)
+}
+
+// normalizeJSONData converts the settings to their json decoded form, so numbers compare equal
+// whatever their Go type. A missing and an empty jsonData are equal.
+func normalizeJSONData(data JSONData) (normalized map[string]interface{}) {
+	if len(data) == 0 {
+		return nil
+	}
+	buf, _ := json.Marshal(data)
+	json.Unmarshal(buf, &normalized)
+	return
+}
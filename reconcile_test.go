@@ -0,0 +1,95 @@
+package grafanaclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var currentDataSources = []DataSource{
+	{ID: 1, UID: "a", Name: "nmon", Type: "influxdb", Access: "proxy", URL: "http://influx:8086", Database: "nmon",
+		JSONData: JSONData{"httpMode": "GET", "timeout": float64(30)}},
+	{ID: 2, UID: "b", Name: "prom", Type: "prometheus", Access: "proxy", URL: "http://prometheus:9090",
+		SecureJSONFields: map[string]bool{"basicAuthPassword": true}},
+	{ID: 3, UID: "c", Name: "legacy", Type: "graphite", Access: "proxy", URL: "http://graphite"},
+}
+
+func Test_PlanDataSources(t *testing.T) {
+	desired := []DataSource{
+		{Name: "nmon", Type: "influxdb", Access: "proxy", URL: "http://influx:8086", Database: "nmon",
+			JSONData: JSONData{"httpMode": "GET", "timeout": 30}},
+		{UID: "b", Name: "prometheus", Type: "prometheus", Access: "proxy", URL: "http://prometheus:9090",
+			SecureJSONData: SecureJSONData{"basicAuthPassword": "pw", "httpHeaderValue1": "token"}},
+		{Name: "loki", Type: "loki", Access: "proxy", URL: "http://loki:3100"},
+	}
+
+	plan := PlanDataSources(currentDataSources, desired, false)
+	assert.Equal(t, []string{"nmon"}, plan.Unchanged, "We are expecting nmon to be in the desired state")
+	assert.Len(t, plan.Changes, 2)
+
+	update := plan.Changes[0]
+	assert.Equal(t, ActionUpdate, update.Action)
+	assert.Equal(t, []string{"name", "secureJsonData.httpHeaderValue1"}, update.Fields)
+	assert.Equal(t, 2, update.Desired.ID, "We are expecting the update to target the existing ID")
+
+	assert.Equal(t, ActionCreate, plan.Changes[1].Action)
+	assert.Equal(t, "loki", plan.Changes[1].Name)
+
+	plan = PlanDataSources(currentDataSources, desired, true)
+	assert.Len(t, plan.Changes, 3)
+	assert.Equal(t, ActionDelete, plan.Changes[2].Action)
+	assert.Equal(t, "legacy", plan.Changes[2].Name)
+	assert.Equal(t, "update prometheus [name secureJsonData.httpHeaderValue1]\ncreate loki\ndelete legacy\n", plan.String())
+}
+
+func Test_ReconcileDataSources(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Write([]byte(`[{"id":3,"uid":"c","name":"legacy","type":"graphite","access":"proxy","url":"http://graphite"}]`))
+			return
+		}
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{"id":4,"message":"Datasource added"}`))
+	}))
+	defer srv.Close()
+
+	session := NewTokenSession("secret", srv.URL)
+	desired := []DataSource{NewLokiDataSource("loki", "http://loki:3100", 1000)}
+
+	plan, err := session.ReconcileDataSources(desired, ReconcileOptions{DryRun: true, Prune: true})
+	assert.Nil(t, err, "We are expecting no error and got one when planning")
+	assert.Len(t, plan.Changes, 2)
+	assert.Empty(t, calls, "We are not expecting changes in dry run mode")
+
+	_, err = session.ReconcileDataSources(desired, ReconcileOptions{Prune: true})
+	assert.Nil(t, err, "We are expecting no error and got one when reconciling")
+	assert.Equal(t, []string{"DELETE /api/datasources/3", "POST /api/datasources"}, calls)
+}
+
+func Test_ReconcileDataSourcesDetails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/datasources":
+			// like Grafana, the list has no basicAuthUser, withCredentials nor secureJsonFields
+			w.Write([]byte(`[{"id":1,"uid":"a","name":"nmon","type":"influxdb","access":"proxy","url":"http://influx:8086","basicAuth":true}]`))
+		case "/api/datasources/1":
+			w.Write([]byte(`{"id":1,"uid":"a","name":"nmon","type":"influxdb","access":"proxy","url":"http://influx:8086","basicAuth":true,
+				"basicAuthUser":"admin","withCredentials":true,"secureJsonFields":{"basicAuthPassword":true}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	session := NewTokenSession("secret", srv.URL)
+	desired := []DataSource{{Name: "nmon", Type: "influxdb", Access: "proxy", URL: "http://influx:8086",
+		BasicAuth: true, BasicAuthUser: "admin", WithCredentials: true, SecureJSONData: SecureJSONData{"basicAuthPassword": "pw"}}}
+
+	plan, err := session.ReconcileDataSources(desired, ReconcileOptions{})
+	assert.Nil(t, err, "We are expecting no error and got one when reconciling")
+	assert.True(t, plan.Empty(), "We are expecting no change and got %s", plan.String())
+	assert.Equal(t, []string{"nmon"}, plan.Unchanged)
+}
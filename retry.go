@@ -0,0 +1,182 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// A RetryPolicy defines how a Session retries the failed requests.
+// Requests are retried on transport errors and on the HTTP status codes listed in RetryStatus.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry. It doubles on each retry.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, including the Retry-After delay.
+	MaxBackoff time.Duration
+	// RetryStatus lists the HTTP status codes to retry.
+	RetryStatus []int
+	// RetryNonIdempotent allows to retry POST and PATCH requests.
+	RetryNonIdempotent bool
+}
+
+// NewRetryPolicy create a retry policy with default values.
+// It makes 3 attempts with a backoff between 500ms and 10s on 429, 502, 503 and 504 errors.
+// Only idempotent requests are retried.
+func NewRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		RetryStatus: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// backoff reports if the attempt must be retried and how long to wait before the next one.
+// A nil policy never retries.
+func (p *RetryPolicy) backoff(method string, attempt int, response *http.Response, err error) (time.Duration, bool) {
+	if p == nil || err == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	if !p.RetryNonIdempotent && !isIdempotent(method) {
+		return 0, false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+	if response != nil && !p.retryStatus(response.StatusCode) {
+		return 0, false
+	}
+
+	if response != nil {
+		if delay, ok := retryAfter(response.Header.Get("Retry-After")); ok {
+			return p.capBackoff(delay), true
+		}
+	}
+
+	delay := p.MinBackoff << uint(attempt-1)
+	if delay <= 0 {
+		delay = p.MaxBackoff
+	}
+	delay = p.capBackoff(delay)
+
+	// equal jitter: wait at least half of the delay
+	half := int64(delay / 2)
+	if half > 0 {
+		delay = time.Duration(half + rand.Int63n(half+1))
+	}
+	return delay, true
+}
+
+// capBackoff limits the delay to MaxBackoff when it is set.
+func (p *RetryPolicy) capBackoff(delay time.Duration) time.Duration {
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return delay
+}
+
+// retryStatus reports if the status code is part of RetryStatus.
+func (p *RetryPolicy) retryStatus(code int) bool {
+	for _, status := range p.RetryStatus {
+		if status == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdempotent reports if the http method can be safely sent more than once.
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a http date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for the given delay or until the context is done.
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimiter spaces out the requests of a Session.
+// It implements a generic cell rate algorithm allowing bursts of requests.
+type rateLimiter struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	tolerance time.Duration
+	next      time.Time
+}
+
+// newRateLimiter create a rate limiter allowing rate requests per second with bursts of burst requests.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	interval := time.Duration(float64(time.Second) / rate)
+	return &rateLimiter{interval: interval, tolerance: time.Duration(burst-1) * interval}
+}
+
+// wait blocks until a request is allowed or the context is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now) - l.tolerance
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	return sleepContext(ctx, delay)
+}
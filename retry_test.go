@@ -0,0 +1,97 @@
+package grafanaclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFlakyServer(failures int32, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(hits, 1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("[]"))
+	}))
+}
+
+func newRetrySession(t *testing.T, url string) *Session {
+	policy := NewRetryPolicy()
+	policy.MinBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+
+	session, err := NewSessionWithOptions(url, WithToken("secret"), WithRetryPolicy(policy))
+	assert.Nil(t, err, "We are expecting no error and got one creating the session")
+	return session
+}
+
+func Test_RetryIdempotentRequest(t *testing.T) {
+	var hits int32
+	srv := newFlakyServer(2, &hits)
+	defer srv.Close()
+
+	session := newRetrySession(t, srv.URL)
+	_, err := session.GetDataSourceList()
+	assert.Nil(t, err, "We are expecting no error after retries")
+	assert.Equal(t, int32(3), hits, "We are expecting 3 attempts")
+}
+
+func Test_RetryGivesUp(t *testing.T) {
+	var hits int32
+	srv := newFlakyServer(10, &hits)
+	defer srv.Close()
+
+	session := newRetrySession(t, srv.URL)
+	_, err := session.GetDataSourceList()
+	var gErr GrafanaError
+	assert.True(t, errors.As(err, &gErr), "We are expecting a GrafanaError")
+	assert.Equal(t, http.StatusServiceUnavailable, gErr.Code)
+	assert.Equal(t, int32(3), hits, "We are expecting MaxAttempts attempts")
+}
+
+func Test_NoRetryForPost(t *testing.T) {
+	var hits int32
+	srv := newFlakyServer(1, &hits)
+	defer srv.Close()
+
+	session := newRetrySession(t, srv.URL)
+	_, err := session.CreateDataSource(ds)
+	assert.NotNil(t, err, "We are expecting an error without retry")
+	assert.Equal(t, int32(1), hits, "We are expecting a single attempt for POST")
+}
+
+func Test_RetryAfter(t *testing.T) {
+	delay, ok := retryAfter("2")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, delay)
+
+	delay, ok = retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.True(t, delay > 59*time.Minute, "We are expecting the delay until the date")
+
+	_, ok = retryAfter("soon")
+	assert.False(t, ok)
+}
+
+func Test_RateLimit(t *testing.T) {
+	var hits int32
+	srv := newFlakyServer(0, &hits)
+	defer srv.Close()
+
+	session, err := NewSessionWithOptions(srv.URL, WithRateLimit(50, 2))
+	assert.Nil(t, err, "We are expecting no error and got one creating the session")
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		_, err = session.GetDataSourceList()
+		assert.Nil(t, err, "We are expecting no error and got one getting DataSource")
+	}
+	assert.True(t, time.Since(start) >= 70*time.Millisecond, "We are expecting the requests to be spaced out")
+}
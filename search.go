@@ -0,0 +1,198 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	neturl "net/url"
+	"strconv"
+)
+
+// Types of SearchHit
+const (
+	SearchTypeDashboard = "dash-db"
+	SearchTypeFolder    = "dash-folder"
+)
+
+// defaultSearchLimit is the number of hits requested per page when SearchQuery.Limit is not set.
+const defaultSearchLimit = 1000
+
+// A SearchQuery contains the filters of a dashboard search.
+// Empty filters are not sent.
+type SearchQuery struct {
+	// Query matches the dashboard and folder titles.
+	Query string
+	// Tags keeps the dashboards having all the tags.
+	Tags []string
+	// Type is SearchTypeDashboard or SearchTypeFolder.
+	Type          string
+	FolderIDs     []int
+	FolderUIDs    []string
+	DashboardUIDs []string
+	Starred       bool
+	// Limit is the number of hits requested per page, 1000 by default.
+	Limit int
+}
+
+// values returns the query parameters of a search page.
+func (q SearchQuery) values(page int) neturl.Values {
+	params := neturl.Values{}
+	if q.Query != "" {
+		params.Set("query", q.Query)
+	}
+	for _, tag := range q.Tags {
+		params.Add("tag", tag)
+	}
+	if q.Type != "" {
+		params.Set("type", q.Type)
+	}
+	for _, id := range q.FolderIDs {
+		params.Add("folderIds", strconv.Itoa(id))
+	}
+	for _, uid := range q.FolderUIDs {
+		params.Add("folderUIDs", uid)
+	}
+	for _, uid := range q.DashboardUIDs {
+		params.Add("dashboardUIDs", uid)
+	}
+	if q.Starred {
+		params.Set("starred", "true")
+	}
+	params.Set("limit", strconv.Itoa(q.limit()))
+	params.Set("page", strconv.Itoa(page))
+	return params
+}
+
+// limit returns the page size of the query.
+func (q SearchQuery) limit() int {
+	if q.Limit > 0 {
+		return q.Limit
+	}
+	return defaultSearchLimit
+}
+
+// A SearchHit contains a dashboard or folder found by a search.
+type SearchHit struct {
+	ID          int      `json:"id"`
+	UID         string   `json:"uid"`
+	Title       string   `json:"title"`
+	URI         string   `json:"uri"`
+	URL         string   `json:"url"`
+	Slug        string   `json:"slug"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+	IsStarred   bool     `json:"isStarred"`
+	FolderID    int      `json:"folderId"`
+	FolderUID   string   `json:"folderUid"`
+	FolderTitle string   `json:"folderTitle"`
+	FolderURL   string   `json:"folderUrl"`
+}
+
+// A SearchIterator walks through the hits of a search, requesting the pages as needed.
+//
+//	it := session.Search(SearchQuery{Tags: []string{"nmon"}})
+//	for it.Next() {
+//		hit := it.Hit()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type SearchIterator struct {
+	session *Session
+	ctx     context.Context
+	query   SearchQuery
+	page    int
+	hits    []SearchHit
+	first   SearchHit
+	index   int
+	done    bool
+	err     error
+}
+
+// Search returns a iterator over the dashboards and folders matching the query.
+// No request is sent before the first call to Next.
+func (s *Session) Search(query SearchQuery) *SearchIterator {
+	return s.SearchContext(context.Background(), query)
+}
+
+// SearchContext is like Search but uses the provided context for the requests.
+func (s *Session) SearchContext(ctx context.Context, query SearchQuery) *SearchIterator {
+	return &SearchIterator{session: s, ctx: ctx, query: query, index: -1}
+}
+
+// Next advances to the next hit, requesting the next page when the current one is consumed.
+// It returns false at the end of the results or when a request fails.
+func (it *SearchIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.index+1 < len(it.hits) {
+		it.index++
+		return true
+	}
+	if it.done {
+		return false
+	}
+
+	it.page++
+	reqURL := it.session.url + "/api/search?" + it.query.values(it.page).Encode()
+	var hits []SearchHit
+	if it.err = it.session.doJSON(it.ctx, "GET", reqURL, nil, &hits); it.err != nil {
+		return false
+	}
+
+	// servers without paging support return the first page again
+	if len(hits) > 0 && it.page > 1 && hits[0].ID == it.first.ID && hits[0].UID == it.first.UID {
+		hits = nil
+	}
+	if len(hits) < it.query.limit() {
+		it.done = true
+	}
+	if it.page == 1 && len(hits) > 0 {
+		it.first = hits[0]
+	}
+
+	it.hits = hits
+	it.index = 0
+	return len(hits) > 0
+}
+
+// Hit returns the current hit.
+func (it *SearchIterator) Hit() SearchHit {
+	if it.index < 0 || it.index >= len(it.hits) {
+		return SearchHit{}
+	}
+	return it.hits[it.index]
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// SearchDashboards returns every dashboard and folder matching the query.
+// It returns a error if a page cannot be retrieved.
+func (s *Session) SearchDashboards(query SearchQuery) ([]SearchHit, error) {
+	return s.SearchDashboardsContext(context.Background(), query)
+}
+
+// SearchDashboardsContext is like SearchDashboards but uses the provided context for the requests.
+func (s *Session) SearchDashboardsContext(ctx context.Context, query SearchQuery) (hits []SearchHit, err error) {
+	it := s.SearchContext(ctx, query)
+	for it.Next() {
+		hits = append(hits, it.Hit())
+	}
+	return hits, it.Err()
+}
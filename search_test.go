@@ -0,0 +1,103 @@
+package grafanaclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SearchQueryValues(t *testing.T) {
+	query := SearchQuery{
+		Query:         "nmon",
+		Tags:          []string{"aix", "power"},
+		Type:          SearchTypeDashboard,
+		FolderIDs:     []int{1, 2},
+		FolderUIDs:    []string{"ops"},
+		DashboardUIDs: []string{"a", "b"},
+		Starred:       true,
+	}
+	params := query.values(2)
+	assert.Equal(t, "nmon", params.Get("query"))
+	assert.Equal(t, []string{"aix", "power"}, params["tag"])
+	assert.Equal(t, "dash-db", params.Get("type"))
+	assert.Equal(t, []string{"1", "2"}, params["folderIds"])
+	assert.Equal(t, []string{"ops"}, params["folderUIDs"])
+	assert.Equal(t, []string{"a", "b"}, params["dashboardUIDs"])
+	assert.Equal(t, "true", params.Get("starred"))
+	assert.Equal(t, "1000", params.Get("limit"))
+	assert.Equal(t, "2", params.Get("page"))
+
+	params = SearchQuery{}.values(1)
+	assert.Equal(t, "", params.Get("query"))
+	assert.Equal(t, "", params.Get("starred"))
+}
+
+func Test_SearchPages(t *testing.T) {
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.Query().Get("page"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		w.Write([]byte("["))
+		for i := 0; i < limit; i++ {
+			id := (page-1)*limit + i + 1
+			if id > 5 {
+				break
+			}
+			if i > 0 {
+				w.Write([]byte(","))
+			}
+			fmt.Fprintf(w, `{"id":%d,"uid":"d%d","title":"Dashboard %d","type":"dash-db","tags":["nmon"],"folderUid":"ops","folderTitle":"Ops"}`, id, id, id)
+		}
+		w.Write([]byte("]"))
+	}))
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	hits, err := session.SearchDashboards(SearchQuery{Limit: 2})
+	assert.Nil(t, err, "We are expecting no error and got one searching")
+	assert.Len(t, hits, 5)
+	assert.Equal(t, []string{"1", "2", "3"}, pages)
+	assert.Equal(t, SearchHit{ID: 5, UID: "d5", Title: "Dashboard 5", Type: SearchTypeDashboard, Tags: []string{"nmon"}, FolderUID: "ops", FolderTitle: "Ops"}, hits[4])
+
+	pages = nil
+	hits, err = session.SearchDashboards(SearchQuery{Limit: 5})
+	assert.Nil(t, err, "We are expecting no error and got one searching")
+	assert.Len(t, hits, 5)
+	assert.Equal(t, []string{"1", "2"}, pages, "We are expecting a empty last page to end the search")
+}
+
+func Test_SearchWithoutPaging(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`[{"id":1,"title":"A"},{"id":2,"title":"B"}]`))
+	}))
+	defer srv.Close()
+
+	it := NewTokenSession("secret", srv.URL).Search(SearchQuery{Limit: 2})
+	var titles []string
+	for it.Next() {
+		titles = append(titles, it.Hit().Title)
+	}
+	assert.Nil(t, it.Err(), "We are expecting no error and got one iterating")
+	assert.Equal(t, []string{"A", "B"}, titles)
+	assert.Equal(t, 2, requests, "We are expecting the repeated page to end the search")
+}
+
+func Test_SearchError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	it := NewTokenSession("secret", srv.URL).Search(SearchQuery{})
+	assert.False(t, it.Next())
+	assert.NotNil(t, it.Err(), "We are expecting a error when the search fails")
+	assert.Equal(t, SearchHit{}, it.Hit())
+}
@@ -0,0 +1,309 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severities of a Finding
+const (
+	// SeverityError is a problem breaking the dashboard or some of its panels.
+	SeverityError = "error"
+	// SeverityWarning is a problem making a panel display nothing.
+	SeverityWarning = "warning"
+)
+
+// A Finding is a problem found in a dashboard by LintDashboard or ValidateDashboard.
+// Path locates the problem, like rows[1].panels[0].targets[2], time or templating.list[0].
+type Finding struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+// String returns the finding like: error rows[0].panels[1]: duplicate panel ID 2
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s: %s", f.Severity, f.Path, f.Message)
+}
+
+// Findings is a list of Finding, in the dashboard order.
+type Findings []Finding
+
+// HasErrors reports if one of the findings is a error.
+func (findings Findings) HasErrors() bool {
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the findings, one per line.
+func (findings Findings) String() string {
+	var lines []string
+	for _, finding := range findings {
+		lines = append(lines, finding.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Datasources which are not defined on the server.
+var builtinDataSources = map[string]bool{
+	"-- Grafana --":   true,
+	"-- Mixed --":     true,
+	"-- Dashboard --": true,
+	"grafana":         true,
+	"default":         true,
+}
+
+// Variables which are provided by Grafana or by the InfluxDB alias patterns.
+var builtinVariables = map[string]bool{
+	"interval":    true,
+	"timeFilter":  true,
+	"timeFrom":    true,
+	"timeTo":      true,
+	"col":         true,
+	"m":           true,
+	"measurement": true,
+}
+
+// Fields of the targets of the other datasources, which contain the query.
+var queryFields = []string{"query", "expr", "rawSql", "target", "queryText"}
+
+// variableReference matches $var, ${var}, ${var:format} and [[var]].
+var variableReference = regexp.MustCompile(`\$\{(\w+)(?::[^}]*)?\}|\[\[(\w+)(?::[^\]]*)?\]\]|\$(\w+)`)
+
+// relativeTime matches the Grafana relative times, like now, now-6h or now-1d/d.
+var relativeTime = regexp.MustCompile(`^now([+-]\d+[smhdwMy])*(/[smhdwMy])?$`)
+
+// Panel types which don't query a datasource.
+var staticPanels = map[string]bool{
+	PanelRow:   true,
+	PanelText:  true,
+	"dashlist": true,
+	"news":     true,
+}
+
+// A linter walks a dashboard and collects the findings.
+type linter struct {
+	findings    Findings
+	variables   map[string]bool
+	panelIDs    map[int]string
+	dataSources map[string]bool
+}
+
+// LintDashboard checks a dashboard without querying the server: duplicate panel IDs,
+// rows with spans over 12, template variables used in the targets but not defined,
+// panels without targets or with empty targets, and a invalid time window.
+// Use ValidateDashboard to also check the panel datasources.
+func LintDashboard(db Dashboard) Findings {
+	return lint(db, nil)
+}
+
+// ValidateDashboard checks a dashboard like LintDashboard, and reports the panels using
+// a datasource which doesn't exist on the server, by name or by reference uid.
+// It returns a error if the datasource list cannot be retrieved.
+func (s *Session) ValidateDashboard(db Dashboard) (Findings, error) {
+	return s.ValidateDashboardContext(context.Background(), db)
+}
+
+// ValidateDashboardContext is like ValidateDashboard but uses the provided context for the request.
+func (s *Session) ValidateDashboardContext(ctx context.Context, db Dashboard) (findings Findings, err error) {
+	list, err := s.GetDataSourceListContext(ctx)
+	if err != nil {
+		return
+	}
+	dataSources := make(map[string]bool)
+	for _, ds := range list {
+		dataSources[ds.Name] = true
+		if ds.UID != "" {
+			dataSources[ds.UID] = true
+		}
+	}
+	return lint(db, dataSources), nil
+}
+
+// lint checks a dashboard, and the panel datasources if dataSources is not nil.
+func lint(db Dashboard, dataSources map[string]bool) Findings {
+	l := &linter{variables: make(map[string]bool), panelIDs: make(map[int]string), dataSources: dataSources}
+	for i, template := range db.Templating.List {
+		path := fmt.Sprintf("templating.list[%d]", i)
+		if template.Name == "" {
+			l.add(SeverityError, path, "template variable has no name")
+			continue
+		}
+		if l.variables[template.Name] {
+			l.add(SeverityError, path, fmt.Sprintf("template variable %s is defined twice", template.Name))
+		}
+		l.variables[template.Name] = true
+	}
+
+	l.checkTime(db.GTime)
+	for i, row := range db.Rows {
+		path := fmt.Sprintf("rows[%d]", i)
+		span := 0
+		for j, panel := range row.Panels {
+			span += panel.Span
+			l.checkPanel(fmt.Sprintf("%s.panels[%d]", path, j), panel)
+		}
+		if span > maxRowSpan {
+			l.add(SeverityError, path, fmt.Sprintf("row spans %d columns, over %d", span, maxRowSpan))
+		}
+	}
+	for i, panel := range db.Panels {
+		path := fmt.Sprintf("panels[%d]", i)
+		l.checkPanel(path, panel)
+		for j, child := range panel.Panels {
+			l.checkPanel(fmt.Sprintf("%s.panels[%d]", path, j), child)
+		}
+	}
+	return l.findings
+}
+
+// add adds a finding.
+func (l *linter) add(severity string, path string, message string) {
+	l.findings = append(l.findings, Finding{Severity: severity, Path: path, Message: message})
+}
+
+// checkTime checks the time window is made of relative or absolute times.
+func (l *linter) checkTime(gtime GTime) {
+	for _, value := range []string{gtime.From, gtime.To} {
+		if value == "" {
+			l.add(SeverityError, "time", "time window is not set")
+			return
+		}
+		if !relativeTime.MatchString(value) && !isAbsoluteTime(value) {
+			l.add(SeverityError, "time", fmt.Sprintf("invalid time %s", value))
+			return
+		}
+	}
+	if gtime.From == gtime.To {
+		l.add(SeverityError, "time", fmt.Sprintf("time window from %s to %s is empty", gtime.From, gtime.To))
+	}
+}
+
+// isAbsoluteTime reports if a time is a date, or a epoch in milliseconds.
+func isAbsoluteTime(value string) bool {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return true
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPanel checks a panel and its targets.
+func (l *linter) checkPanel(path string, panel Panel) {
+	if panel.ID == 0 {
+		l.add(SeverityWarning, path, fmt.Sprintf("panel %q has no ID", panel.Title))
+	} else if first, ok := l.panelIDs[panel.ID]; ok {
+		l.add(SeverityError, path, fmt.Sprintf("duplicate panel ID %d, already used by %s", panel.ID, first))
+	} else {
+		l.panelIDs[panel.ID] = path
+	}
+
+	if staticPanels[panel.Type] {
+		return
+	}
+	l.checkDataSource(path, panel)
+
+	if len(panel.Targets) == 0 {
+		l.add(SeverityWarning, path, fmt.Sprintf("panel %q has no target", panel.Title))
+	}
+	for i, target := range panel.Targets {
+		targetPath := fmt.Sprintf("%s.targets[%d]", path, i)
+		if isEmptyTarget(target) {
+			l.add(SeverityWarning, targetPath, "target has no measurement nor query")
+		}
+		data, err := json.Marshal(target)
+		if err != nil {
+			continue
+		}
+		for _, name := range referencedVariables(string(data)) {
+			if !l.variables[name] {
+				l.add(SeverityError, targetPath, fmt.Sprintf("template variable %s is not defined", name))
+			}
+		}
+	}
+}
+
+// checkDataSource checks the datasource of a panel exists, by name or by reference uid.
+func (l *linter) checkDataSource(path string, panel Panel) {
+	name := panel.DataSource
+	if ref, ok := panel.Extra["datasource"]; ok {
+		var reference struct {
+			UID string `json:"uid"`
+		}
+		json.Unmarshal(ref, &reference)
+		name = reference.UID
+	}
+	if name == "" || builtinDataSources[name] {
+		return
+	}
+
+	if variables := referencedVariables(name); len(variables) > 0 {
+		for _, variable := range variables {
+			if !l.variables[variable] {
+				l.add(SeverityError, path, fmt.Sprintf("datasource variable %s is not defined", variable))
+			}
+		}
+		return
+	}
+	if l.dataSources != nil && !l.dataSources[name] {
+		l.add(SeverityError, path, fmt.Sprintf("datasource %s doesn't exist", name))
+	}
+}
+
+// isEmptyTarget reports if a target has neither a InfluxDB measurement nor a query.
+func isEmptyTarget(target Target) bool {
+	if target.Measurement != "" {
+		return false
+	}
+	for _, field := range queryFields {
+		if value, ok := target.Extra[field]; ok && string(value) != `""` && string(value) != "null" {
+			return false
+		}
+	}
+	return true
+}
+
+// referencedVariables returns the names of the template variables used in a text,
+// without the builtin ones like $__interval, $timeFilter or the $tag_host alias patterns.
+func referencedVariables(text string) (names []string) {
+	seen := make(map[string]bool)
+	for _, match := range variableReference.FindAllStringSubmatch(text, -1) {
+		name := match[1] + match[2] + match[3]
+		if strings.HasPrefix(name, "__") || strings.HasPrefix(name, "tag_") || builtinVariables[name] || seen[name] {
+			continue
+		}
+		if _, err := strconv.Atoi(name); err == nil {
+			// regular expression group, like $1
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package grafanaclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lintedDashboard returns a dashboard with one problem of each kind.
+func lintedDashboard(t *testing.T) Dashboard {
+	var dashboard Dashboard
+	err := json.Unmarshal([]byte(`{"title":"nmon","time":{"from":"now-6h","to":"yesterday"},
+		"templating":{"list":[{"name":"host","type":"query"}]},
+		"rows":[
+			{"title":"CPU","panels":[
+				{"id":1,"type":"graph","span":8,"datasource":"nmon","targets":[{"measurement":"CPU_ALL","tags":[{"key":"host","value":"/^$host$/"}]}]},
+				{"id":2,"type":"graph","span":4,"datasource":"$ds","targets":[{"measurement":"CPU","alias":"$tag_host $col"}]}]},
+			{"title":"Memory","panels":[
+				{"id":3,"type":"text","span":6},
+				{"id":1,"type":"graph","span":4,"datasource":"nmon","targets":[{"measurement":"MEM"},{"alias":"empty"},{"measurement":"MEMNEW","tags":[{"key":"lpar","value":"/^[[lpar]]$/"}]}]},
+				{"id":4,"type":"graph","span":4,"datasource":"influx","targets":[]}]}],
+		"panels":[{"id":5,"type":"timeseries","datasource":{"type":"prometheus","uid":"prom"},
+			"targets":[{"refId":"A","expr":"rate(cpu[$__rate_interval]) / ${cores}"}]}]}`), &dashboard)
+	assert.Nil(t, err, "We are expecting no error and got one decoding dashboard")
+	return dashboard
+}
+
+func Test_LintDashboard(t *testing.T) {
+	findings := LintDashboard(lintedDashboard(t))
+	expected := Findings{
+		{SeverityError, "time", "invalid time yesterday"},
+		{SeverityError, "rows[0].panels[1]", "datasource variable ds is not defined"},
+		{SeverityError, "rows[1].panels[1]", "duplicate panel ID 1, already used by rows[0].panels[0]"},
+		{SeverityWarning, "rows[1].panels[1].targets[1]", "target has no measurement nor query"},
+		{SeverityError, "rows[1].panels[1].targets[2]", "template variable lpar is not defined"},
+		{SeverityWarning, "rows[1].panels[2]", "panel \"\" has no target"},
+		{SeverityError, "rows[1]", "row spans 14 columns, over 12"},
+		{SeverityError, "panels[0].targets[0]", "template variable cores is not defined"},
+	}
+	assert.Equal(t, expected, findings)
+	assert.True(t, findings.HasErrors())
+	assert.Equal(t, "error time: invalid time yesterday", findings[0].String())
+}
+
+func Test_LintTemplate(t *testing.T) {
+	dashboard, err := ConvertTemplate("example.toml")
+	assert.Nil(t, err, "We are expecting no error and got one when Converting template")
+	assert.False(t, LintDashboard(dashboard).HasErrors(), "We are expecting no error in the example template")
+
+	built, _ := NewDashboardBuilder("nmon").Row("CPU").Graph("CPU", 12, NewTarget()).Build()
+	findings := LintDashboard(built)
+	assert.Len(t, findings, 1)
+	assert.Equal(t, "rows[0].panels[0].targets[0]", findings[0].Path)
+}
+
+func Test_IsAbsoluteTime(t *testing.T) {
+	for _, value := range []string{"1700000000000", "2023-11-14T22:13:20Z", "2023-11-14 22:13:20", "2023-11-14"} {
+		assert.True(t, isAbsoluteTime(value), "We are expecting %s to be a absolute time", value)
+	}
+	assert.False(t, isAbsoluteTime("now-6h"))
+	for _, value := range []string{"now", "now-6h", "now-1d/d", "now/w", "now+1h"} {
+		assert.Regexp(t, relativeTime, value)
+	}
+}
+
+func Test_ValidateDashboard(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/datasources", r.URL.Path)
+		w.Write([]byte(`[{"id":1,"uid":"P1","name":"nmon","type":"influxdb"},{"id":2,"uid":"prom","name":"Prometheus","type":"prometheus"}]`))
+	}))
+	defer srv.Close()
+
+	session, _ := NewSessionWithOptions(srv.URL, WithToken("secret"), WithServerVersion("10.2.3"))
+	findings, err := session.ValidateDashboard(lintedDashboard(t))
+	assert.Nil(t, err, "We are expecting no error and got one validating dashboard")
+
+	var missing []Finding
+	for _, finding := range findings {
+		if finding.Message == "datasource influx doesn't exist" {
+			missing = append(missing, finding)
+		}
+	}
+	assert.Equal(t, []Finding{{SeverityError, "rows[1].panels[2]", "datasource influx doesn't exist"}}, missing)
+	assert.Len(t, findings, len(LintDashboard(lintedDashboard(t)))+1, "We are expecting the uid reference to match")
+}
@@ -0,0 +1,148 @@
+// Copyright © 2015 Alain Dejoux <adejoux@djouxtech.net>.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grafanaclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A Version contains a Grafana server version.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	// Raw is the version as reported by the server, like 10.2.3 or 8.5.0-beta1.
+	Raw string
+}
+
+// minVersion is the oldest Grafana version supported by this library.
+var minVersion = Version{Major: 2}
+
+// ParseVersion parses a Grafana version like 10.2.3, v9.1.0 or 11.0.0-preview.
+// It returns a error if the version doesn't start with a major version number.
+func ParseVersion(raw string) (v Version, err error) {
+	v.Raw = raw
+	core := strings.TrimPrefix(strings.TrimSpace(raw), "v")
+	if i := strings.IndexAny(core, "-+ "); i >= 0 {
+		core = core[:i]
+	}
+
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range strings.SplitN(core, ".", len(numbers)) {
+		if *numbers[i], err = strconv.Atoi(part); err != nil {
+			return v, GrafanaError{Description: "invalid Grafana version " + raw, Err: err}
+		}
+	}
+	return
+}
+
+// String returns the version as major.minor.patch.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports if the version is major.minor or newer.
+func (v Version) AtLeast(major int, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+// versionCache keeps the server version detected by a Session.
+// It's shared by the copies returned by ForOrg.
+type versionCache struct {
+	mu      sync.Mutex
+	version *Version
+}
+
+// ServerVersion returns the version of the Grafana server.
+// The version is read from /api/health, or /api/frontend/settings when the health endpoint
+// is missing or hides the version. It's detected on first use and cached on the session.
+// It returns a error if the version cannot be detected.
+func (s *Session) ServerVersion() (Version, error) {
+	return s.ServerVersionContext(context.Background())
+}
+
+// ServerVersionContext is like ServerVersion but uses the provided context for the requests.
+func (s *Session) ServerVersionContext(ctx context.Context) (Version, error) {
+	if s.version == nil {
+		return s.detectVersion(ctx)
+	}
+
+	s.version.mu.Lock()
+	defer s.version.mu.Unlock()
+	if s.version.version != nil {
+		return *s.version.version, nil
+	}
+	v, err := s.detectVersion(ctx)
+	if err != nil {
+		return v, err
+	}
+	s.version.version = &v
+	return v, nil
+}
+
+// detectVersion requests the version from the server.
+func (s *Session) detectVersion(ctx context.Context) (Version, error) {
+	var health struct {
+		Version string `json:"version"`
+	}
+	err := s.doJSON(ctx, "GET", s.url+"/api/health", nil, &health)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Version{}, err
+	}
+	if health.Version != "" {
+		return ParseVersion(health.Version)
+	}
+
+	var settings struct {
+		BuildInfo struct {
+			Version string `json:"version"`
+		} `json:"buildInfo"`
+	}
+	if err = s.doJSON(ctx, "GET", s.url+"/api/frontend/settings", nil, &settings); err != nil {
+		return Version{}, err
+	}
+	if settings.BuildInfo.Version == "" {
+		return Version{}, GrafanaError{Description: "Grafana server doesn't report its version"}
+	}
+	return ParseVersion(settings.BuildInfo.Version)
+}
+
+// requireVersion returns the server version, or a error matching ErrUnsupportedVersion
+// if the server is older than major.minor or than the oldest supported version.
+func (s *Session) requireVersion(ctx context.Context, major int, minor int, feature string) (Version, error) {
+	v, err := s.ServerVersionContext(ctx)
+	if err != nil {
+		return v, err
+	}
+	if !v.AtLeast(minVersion.Major, minVersion.Minor) {
+		return v, unsupportedVersion(v, "this library")
+	}
+	if !v.AtLeast(major, minor) {
+		return v, unsupportedVersion(v, feature)
+	}
+	return v, nil
+}
+
+// unsupportedVersion returns the error reported when a feature is not available with the server version.
+func unsupportedVersion(v Version, feature string) error {
+	return GrafanaError{Description: fmt.Sprintf("Grafana %s is not supported by %s", v.Raw, feature), Err: ErrUnsupportedVersion}
+}
@@ -0,0 +1,118 @@
+package grafanaclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseVersion(t *testing.T) {
+	v, err := ParseVersion("10.2.3")
+	assert.Nil(t, err, "We are expecting no error and got one parsing version")
+	assert.Equal(t, Version{Major: 10, Minor: 2, Patch: 3, Raw: "10.2.3"}, v)
+
+	v, err = ParseVersion("v8.5.0-beta1")
+	assert.Nil(t, err, "We are expecting no error and got one parsing a pre-release version")
+	assert.Equal(t, "8.5.0", v.String())
+	assert.True(t, v.AtLeast(8, 5))
+	assert.True(t, v.AtLeast(6, 7))
+	assert.False(t, v.AtLeast(8, 6))
+	assert.False(t, v.AtLeast(9, 0))
+
+	_, err = ParseVersion("unknown")
+	assert.NotNil(t, err, "We are expecting a error parsing a invalid version")
+}
+
+func Test_ServerVersionCached(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"commit":"abc","database":"ok","version":"8.5.2"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	v, err := session.ServerVersion()
+	assert.Nil(t, err, "We are expecting no error and got one detecting version")
+	assert.Equal(t, 8, v.Major)
+
+	v, err = session.ForOrg(2).ServerVersion()
+	assert.Nil(t, err, "We are expecting no error and got one getting cached version")
+	assert.Equal(t, "8.5.2", v.Raw)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "We are expecting the version to be detected once")
+}
+
+func Test_ServerVersionFrontendSettings(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
+		// version hidden to anonymous requests
+		w.Write([]byte(`{"database":"ok"}`))
+	})
+	mux.HandleFunc("/api/frontend/settings", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"buildInfo":{"version":"6.7.4","commit":"abc"}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	v, err := NewTokenSession("secret", srv.URL).ServerVersion()
+	assert.Nil(t, err, "We are expecting no error and got one detecting version from settings")
+	assert.Equal(t, "6.7.4", v.String())
+
+	session, err := NewSessionWithOptions(srv.URL, WithServerVersion("10.0.0"))
+	assert.Nil(t, err, "We are expecting no error and got one setting the version")
+	v, err = session.ServerVersion()
+	assert.Nil(t, err, "We are expecting no error and got one getting the configured version")
+	assert.Equal(t, 10, v.Major)
+
+	_, err = NewSessionWithOptions(srv.URL, WithServerVersion("latest"))
+	assert.NotNil(t, err, "We are expecting a error with a invalid version")
+}
+
+func Test_UnsupportedVersion(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/frontend/settings", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"buildInfo":{"version":"1.9.1"}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	session := NewTokenSession("secret", srv.URL)
+
+	_, err := session.GetDashboard("nmon")
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion), "We are expecting a unsupported version error")
+
+	session, _ = NewSessionWithOptions(srv.URL, WithServerVersion("4.6.3"))
+	_, err = session.GetDashboardByUID("nmon")
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion), "We are expecting a unsupported version error")
+}
+
+func Test_GetDataSourcePluginsByVersion(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/datasources/plugins", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"influxdb":{"name":"InfluxDB","type":"influxdb","pluginType":"datasource"}}`))
+	})
+	mux.HandleFunc("/api/plugins", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "datasource", r.URL.Query().Get("type"))
+		w.Write([]byte(`[{"name":"Prometheus","type":"datasource","id":"prometheus","enabled":true}]`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	session, _ := NewSessionWithOptions(srv.URL, WithServerVersion("2.6.0"))
+	plugins, err := session.GetDataSourcePlugins()
+	assert.Nil(t, err, "We are expecting no error and got one listing Grafana 2 plugins")
+	assert.Equal(t, "InfluxDB", plugins["influxdb"].Name)
+
+	_, err = session.GetPlugins("datasource")
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion), "We are expecting a unsupported version error")
+
+	session, _ = NewSessionWithOptions(srv.URL, WithServerVersion("10.2.3"))
+	plugins, err = session.GetDataSourcePlugins()
+	assert.Nil(t, err, "We are expecting no error and got one listing plugins")
+	assert.Equal(t, DataSourcePlugin{Name: "Prometheus", PluginType: "datasource", Type: "prometheus"}, plugins["prometheus"])
+}